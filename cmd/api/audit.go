@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/audit"
+)
+
+// runAuditVerify checks the audit hash chain. It exits non-zero if the
+// chain is broken, so it can run as a periodic compliance check.
+func runAuditVerify(ctx context.Context, args []string) error {
+	fs := newFlagSet("audit verify", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	pool, err := connectDatabase(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	result, err := audit.NewLog(pool).Verify(ctx)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+	if !result.Valid {
+		return fmt.Errorf("audit chain broken at event %d: %s", result.BrokenAt, result.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// runConfigValidate loads and validates configuration from the
+// environment, optionally printing it with secrets masked
+func runConfigValidate(ctx context.Context, args []string) error {
+	fs := newFlagSet("config validate", "")
+	printConfig := fs.Bool("print", false, "print the effective configuration as JSON, secrets masked")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, _, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	if *printConfig {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cfg.Redacted())
+	}
+
+	fmt.Fprintf(os.Stdout, "configuration is valid (environment: %s)\n", cfg.App.Environment)
+	return nil
+}
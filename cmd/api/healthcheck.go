@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+)
+
+// runHealthcheck probes the /health endpoint of a running instance, for
+// container health checks where no curl is available. It exits with
+// exitUnavailable when the instance is unhealthy.
+func runHealthcheck(ctx context.Context, args []string) error {
+	fs := newFlagSet("healthcheck", "")
+	url := fs.String("url", "", "health URL (default: /health on the local APP_PORT)")
+	timeout := fs.Duration("timeout", 3*time.Second, "overall timeout")
+	checkDB := fs.Bool("db", false, "also ping the database")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	target := *url
+	if target == "" {
+		scheme := "http"
+		if cfg.Server.TLS.Enabled() {
+			// The probe runs next to the server and only checks liveness;
+			// the certificate is for the public name, not localhost
+			scheme = "https"
+			transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		}
+		target = fmt.Sprintf("%s://%s/health", scheme, net.JoinHostPort("127.0.0.1", fmt.Sprint(cfg.App.Port)))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return usageErrorf("invalid URL %q: %v", target, err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return &exitError{code: exitUnavailable, err: fmt.Errorf("health check failed: %w", err)}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return &exitError{code: exitUnavailable, err: fmt.Errorf("health check returned %s", resp.Status)}
+	}
+
+	if *checkDB {
+		pool, err := database.NewPostgresPool(ctx, &cfg.Database, log)
+		if err != nil {
+			return &exitError{code: exitUnavailable, err: fmt.Errorf("database check failed: %w", err)}
+		}
+		pool.Close()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/F1sssss/Perfect_Trade/internal/instruments"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// runInstrumentsImport upserts instruments from a CSV file in a single
+// transaction. With --dry-run the file is only validated.
+func runInstrumentsImport(ctx context.Context, args []string) error {
+	fs := newFlagSet("instruments import", "")
+	file := fs.String("file", "", "CSV file with a header row, or - for stdin (required)")
+	dryRun := fs.Bool("dry-run", false, "validate the file without writing")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return usageErrorf("--file is required")
+	}
+
+	in := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return usageErrorf("failed to open %s: %v", *file, err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	list, err := instruments.ParseCSV(in)
+	if err != nil {
+		return fmt.Errorf("invalid instruments file: %w", err)
+	}
+
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		log.Info("instruments file is valid", logger.Int("rows", len(list)))
+		return nil
+	}
+
+	pool, err := connectDatabase(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	result, err := instruments.NewRepository(pool).Import(ctx, list)
+	if err != nil {
+		return err
+	}
+
+	log.Info("instruments imported",
+		logger.Int("inserted", result.Inserted),
+		logger.Int("updated", result.Updated),
+	)
+	return nil
+}
@@ -2,53 +2,202 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"net/http"
 	"os"
+	"sort"
+	"strings"
+	_ "time/tzdata" // scheduler time zones on hosts without zoneinfo
 
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
 	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
 	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
-	"github.com/F1sssss/Perfect_Trade/internal/shared/server"
 )
 
+// Exit codes shared by all commands, so scripts and orchestrators can
+// tell a bad invocation from an unreachable dependency
+const (
+	exitOK          = 0
+	exitFailure     = 1
+	exitUsage       = 2
+	exitConfig      = 3
+	exitUnavailable = 4
+)
+
+// exitError carries the process exit code for an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// usageErrorf reports an invalid invocation
+func usageErrorf(format string, args ...interface{}) error {
+	return &exitError{code: exitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// command is a CLI subcommand. Commands with subcommands leave run nil.
+type command struct {
+	summary     string
+	run         func(ctx context.Context, args []string) error
+	subcommands map[string]*command
+}
+
+// commands lists the top-level commands; serve is the default
+var commands = map[string]*command{
+	"serve": {summary: "Start the HTTP API (default)", run: runServe},
+	"migrate": {summary: "Apply or roll back database migrations", subcommands: map[string]*command{
+		"up":     {summary: "Apply pending migrations", run: runMigrateUp},
+		"down":   {summary: "Roll back applied migrations", run: runMigrateDown},
+		"status": {summary: "List migrations and whether they are applied", run: runMigrateStatus},
+	}},
+	"config": {summary: "Inspect configuration", subcommands: map[string]*command{
+		"validate": {summary: "Load and validate configuration", run: runConfigValidate},
+	}},
+	"user": {summary: "Manage users", subcommands: map[string]*command{
+		"create-admin": {summary: "Create an admin user", run: runUserCreateAdmin},
+	}},
+	"instruments": {summary: "Manage instruments", subcommands: map[string]*command{
+		"import": {summary: "Import instruments from a CSV file", run: runInstrumentsImport},
+	}},
+	"audit": {summary: "Inspect the audit log", subcommands: map[string]*command{
+		"verify": {summary: "Check the audit hash chain for tampering", run: runAuditVerify},
+	}},
+	"tenant": {summary: "Manage tenants", subcommands: map[string]*command{
+		"create": {summary: "Create a tenant", run: runTenantCreate},
+		"show":   {summary: "Show a tenant and its effective settings", run: runTenantShow},
+	}},
+	"seed":        {summary: "Load development data", run: runSeed},
+	"healthcheck": {summary: "Check a running instance, for container health probes", run: runHealthcheck},
+}
+
 func main() {
-	// Run application and exit with appropriate code
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+	// Run the command and exit with its code
+	os.Exit(execute(context.Background(), os.Args[1:]))
+}
+
+// execute dispatches args to a command and maps its error to an exit code
+func execute(ctx context.Context, args []string) int {
+	err := dispatch(ctx, nil, commands, args)
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	if apperrors.Is(err, apperrors.ErrValidation) || apperrors.Is(err, apperrors.ErrInvalidInput) {
+		return exitUsage
+	}
+	return exitFailure
+}
+
+// dispatch finds the command named by args, descending into subcommands
+func dispatch(ctx context.Context, path []string, cmds map[string]*command, args []string) error {
+	if len(path) == 0 && (len(args) == 0 || strings.HasPrefix(args[0], "-") && !isHelp(args[0])) {
+		// No command, or flags only: serve
+		return runServe(ctx, args)
+	}
+	if len(args) == 0 || isHelp(args[0]) {
+		printUsage(path, cmds)
+		if len(args) == 0 {
+			return usageErrorf("missing command")
+		}
+		return nil
+	}
+
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		printUsage(path, cmds)
+		return usageErrorf("unknown command %q", strings.Join(append(path, args[0]), " "))
 	}
+	path = append(path, args[0])
+	if cmd.subcommands != nil {
+		return dispatch(ctx, path, cmd.subcommands, args[1:])
+	}
+	return cmd.run(ctx, args[1:])
+}
+
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// printUsage lists the commands available at path
+func printUsage(path []string, cmds map[string]*command) {
+	prefix := strings.Join(append([]string{"api"}, path...), " ")
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", prefix)
+
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, cmds[name].summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for command flags.\n", prefix)
+}
+
+// newFlagSet creates a flag set for a command; usage describes the
+// positional arguments, if any
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: api %s [flags]", name)
+		if usage != "" {
+			fmt.Fprintf(fs.Output(), " %s", usage)
+		}
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	return fs
 }
 
-func run() error {
-	ctx := context.Background()
+// parseFlags parses args, reporting bad flags as usage errors
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &exitError{code: exitUsage, err: err}
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageErrorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return nil
+}
 
-	// 1. Load configuration
+// bootstrap loads configuration and creates the logger shared by all
+// commands
+func bootstrap() (*config.Config, logger.Logger, error) {
 	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return nil, nil, &exitError{code: exitConfig, err: fmt.Errorf("failed to load config: %w", err)}
 	}
 
-	// 2. Setup logger
 	log, err := logger.NewLogger(&cfg.App)
 	if err != nil {
-		return fmt.Errorf("failed to create logger: %w", err)
+		return nil, nil, &exitError{code: exitConfig, err: fmt.Errorf("failed to create logger: %w", err)}
 	}
+	return cfg, log, nil
+}
 
-	log.Info("starting application",
-		logger.String("environment", cfg.App.Environment),
-		logger.Int("port", cfg.App.Port),
-	)
-
-	// 3. Setup database
-	pool, err := database.NewPostgresPool(ctx, &cfg.Database)
+// connectDatabase opens the connection pool; the caller closes it. In
+// production it refuses roles that bypass row-level security.
+func connectDatabase(ctx context.Context, cfg *config.Config, log logger.Logger) (*pgxpool.Pool, error) {
+	pool, err := database.NewPostgresPool(ctx, &cfg.Database, log)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, &exitError{code: exitUnavailable, err: fmt.Errorf("failed to connect to database: %w", err)}
 	}
-	defer database.Close(pool)
 
 	log.Info("database connection established",
 		logger.String("host", cfg.Database.Host),
@@ -56,36 +205,13 @@ func run() error {
 		logger.String("database", cfg.Database.Name),
 	)
 
-	// 4. Setup router
-	router := setupRouter()
-
-	// 5. Setup and start HTTP server
-	srv := server.NewServer(router, &cfg.Server, log)
-
-	return srv.Start(cfg.App.Port)
-}
-
-func setupRouter() *chi.Mux {
-	router := chi.NewRouter()
-
-	// Middleware
-	router.Use(middleware.RequestID)
-	router.Use(middleware.RealIP)
-	router.Use(middleware.Logger)
-	router.Use(middleware.Recoverer)
-
-	// Health check endpoint
-	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok"}`))
-	})
-
-	// API routes (will add later)
-	router.Route("/api/v1", func(r chi.Router) {
-		// TODO: Register module routes here
-		// r.Mount("/orders", orderHandler.Routes())
-	})
-
-	return router
+	// Tenant isolation relies on row-level security, which superusers skip
+	if err := database.CheckRowSecurity(ctx, pool); err != nil {
+		if cfg.IsProduction() {
+			pool.Close()
+			return nil, &exitError{code: exitConfig, err: err}
+		}
+		log.Warn("tenant isolation disabled", logger.Error(err))
+	}
+	return pool, nil
 }
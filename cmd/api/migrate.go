@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/migrate"
+)
+
+// withMigrator connects to the database and runs fn with a migrator
+func withMigrator(ctx context.Context, fn func(m *migrate.Migrator, log logger.Logger) error) error {
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	pool, err := connectDatabase(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	m, err := migrate.NewMigrator(pool, log)
+	if err != nil {
+		return err
+	}
+	return fn(m, log)
+}
+
+// runMigrateUp applies pending migrations
+func runMigrateUp(ctx context.Context, args []string) error {
+	fs := newFlagSet("migrate up", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return withMigrator(ctx, func(m *migrate.Migrator, log logger.Logger) error {
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Info("migrations complete", logger.Int("applied", applied))
+		return nil
+	})
+}
+
+// runMigrateDown rolls back the most recent migrations
+func runMigrateDown(ctx context.Context, args []string) error {
+	fs := newFlagSet("migrate down", "")
+	steps := fs.Int("steps", 1, "number of migrations to roll back")
+	all := fs.Bool("all", false, "roll back every applied migration")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *all {
+		*steps = int(^uint(0) >> 1)
+	}
+	if *steps < 1 {
+		return usageErrorf("--steps must be at least 1")
+	}
+
+	return withMigrator(ctx, func(m *migrate.Migrator, log logger.Logger) error {
+		rolledBack, err := m.Down(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		log.Info("rollback complete", logger.Int("rolled_back", rolledBack))
+		return nil
+	})
+}
+
+// runMigrateStatus prints every migration and when it was applied. It
+// exits non-zero when migrations are pending if --check is set, for use
+// in deploy pipelines.
+func runMigrateStatus(ctx context.Context, args []string) error {
+	fs := newFlagSet("migrate status", "")
+	check := fs.Bool("check", false, "fail if any migration is pending")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	return withMigrator(ctx, func(m *migrate.Migrator, log logger.Logger) error {
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		pending := 0
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format(time.RFC3339)
+				if st.Modified {
+					applied += " (modified since applied)"
+				}
+			} else {
+				pending++
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		tw.Flush()
+
+		if *check && pending > 0 {
+			return fmt.Errorf("%d migration(s) pending", pending)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/F1sssss/Perfect_Trade/internal/instruments"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+	"github.com/F1sssss/Perfect_Trade/internal/users"
+)
+
+// Development accounts; the passwords are public, so seeding refuses to
+// run in production unless forced
+var seedUsers = []struct {
+	email, password, role string
+}{
+	{"admin@perfect-trade.local", "admin-password-dev", users.RoleAdmin},
+	{"trader@perfect-trade.local", "trader-password-dev", users.RoleTrader},
+	{"viewer@perfect-trade.local", "viewer-password-dev", users.RoleViewer},
+}
+
+// seedInstruments is a small CSV of common instruments
+const seedInstruments = `symbol,name,asset_class,currency,tick_size,lot_size,price_scale,quantity_scale
+AAPL,Apple Inc.,equity,USD,0.01,1,2,0
+MSFT,Microsoft Corp.,equity,USD,0.01,1,2,0
+SPY,SPDR S&P 500 ETF Trust,etf,USD,0.01,1,2,0
+EURUSD,Euro / US Dollar,fx,USD,0.00001,1000,5,0
+BTCUSD,Bitcoin / US Dollar,crypto,USD,0.01,0.00001,2,5
+`
+
+// runSeed loads development users and instruments. It is idempotent:
+// existing users are kept and instruments are upserted.
+func runSeed(ctx context.Context, args []string) error {
+	fs := newFlagSet("seed", "")
+	force := fs.Bool("force", false, "allow seeding a production database")
+	tenantID := fs.String("tenant", tenant.Default, "tenant the users belong to")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if !tenant.ValidID(*tenantID) {
+		return usageErrorf("invalid --tenant %q", *tenantID)
+	}
+
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+	if cfg.IsProduction() && !*force {
+		return usageErrorf("refusing to seed in production without --force")
+	}
+
+	pool, err := connectDatabase(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	// 1. Users, one transaction each so an existing user doesn't abort
+	// the rest
+	userRepo := users.NewRepository(pool)
+	tm := database.NewPostgresTransactionManager(pool)
+	tenantCtx := tenant.WithID(ctx, *tenantID)
+	for _, u := range seedUsers {
+		_, err := tm.WithTransaction(tenantCtx, func(ctx context.Context) (interface{}, error) {
+			return userRepo.Create(ctx, u.email, u.password, u.role)
+		})
+		if apperrors.Is(err, apperrors.ErrAlreadyExists) {
+			log.Info("seed user exists, skipping", logger.String("email", u.email))
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		log.Info("seed user created", logger.String("email", u.email), logger.String("role", u.role))
+	}
+
+	// 2. Instruments
+	list, err := instruments.ParseCSV(strings.NewReader(seedInstruments))
+	if err != nil {
+		return err
+	}
+	result, err := instruments.NewRepository(pool).Import(ctx, list)
+	if err != nil {
+		return err
+	}
+
+	log.Info("seed complete",
+		logger.Int("instruments_inserted", result.Inserted),
+		logger.Int("instruments_updated", result.Updated),
+	)
+	return nil
+}
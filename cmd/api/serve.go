@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/F1sssss/Perfect_Trade/internal/instruments"
+	"github.com/F1sssss/Perfect_Trade/internal/orders"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/admin"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/audit"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/auth"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/cache"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/clock"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/flags"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/id"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/jobs"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/openapi"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/pagination"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/request"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/scheduler"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/server"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+)
+
+// runServe starts the HTTP API and runs until shutdown
+func runServe(ctx context.Context, args []string) error {
+	fs := newFlagSet("serve", "")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	// 1. Load configuration and setup logger
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	log.Info("starting application",
+		logger.String("environment", cfg.App.Environment),
+		logger.Int("port", cfg.App.Port),
+	)
+
+	// Business time; components and requests find it in their context
+	clk, err := clock.New(&cfg.App)
+	if err != nil {
+		return err
+	}
+	if cfg.App.SimulatedTime != "" {
+		log.Warn("running at simulated time", logger.Time("now", clk.Now()))
+	}
+	ctx = clock.WithClock(ctx, clk)
+
+	// 2. Setup database
+	pool, err := connectDatabase(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewCluster(ctx, &cfg.Database, pool, log)
+	if err != nil {
+		pool.Close()
+		return err
+	}
+	bus := database.NewBus(pool, log)
+
+	// Scheduled work runs only on the elected instance; queued jobs run
+	// on every instance with workers enabled
+	elector := database.NewElector(pool, "scheduler", cfg.Database.LeaderRenewInterval, log)
+	jobQueue := jobs.NewQueue(pool, bus, &cfg.Jobs, log)
+	auditLog := audit.NewLog(pool)
+	featureFlags := flags.NewService(pool, bus, log)
+
+	// Reference data read on every order is cached; writes on any
+	// instance evict it everywhere
+	caches := cache.NewInvalidator(bus, log)
+	instrumentCache := instruments.NewCache()
+	tenantCache := tenant.NewCache()
+	caches.Attach(instrumentCache, tenantCache)
+	sched := scheduler.New(pool, log).WithClock(clk)
+	sched.MustAdd(sched.PruneTask(90 * 24 * time.Hour))
+	elector.OnElected(sched.Run)
+
+	// Modules
+	ids, err := id.NewGenerator(cfg.App.NodeID)
+	if err != nil {
+		pool.Close()
+		return err
+	}
+	ids.WithClock(clk)
+	orderService := orders.NewService(db,
+		instruments.NewRepository(pool).WithCluster(db).WithCache(instrumentCache),
+		tenant.NewRegistry(pool, cfg).WithCache(tenantCache),
+		auditLog, ids)
+	orderHandler := orders.NewHandler(orderService, pagination.New(&cfg.Pagination), log)
+
+	// 3. Setup router and API contract
+	spec := openapi.NewSpec(openapi.Info{
+		Title:   "Perfect Trade API",
+		Version: "v1",
+	}, "/api/v1")
+	router := setupRouter(cfg, log, spec, clk, orderHandler)
+
+	// 4. Setup HTTP server
+	srv := server.NewServer(router, &cfg.Server, log)
+
+	// 5. Register components; they stop in reverse order so in-flight
+	// requests finish before the pool is closed
+	app := lifecycle.NewManager(&cfg.Lifecycle, log)
+	app.MustRegister(database.Component(pool))
+	app.MustRegister(db.Component())
+	app.MustRegister(bus.Component())
+	app.MustRegister(elector.Component())
+	app.MustRegister(featureFlags.Component())
+	app.MustRegister(caches.Component())
+	if cfg.Jobs.Enabled {
+		app.MustRegister(jobQueue.Component())
+	}
+	app.MustRegister(srv.Component("http", fmt.Sprintf(":%d", cfg.App.Port), "database", "database-replicas", "feature-flags"))
+
+	// 6. Setup admin listener on an internal address
+	if cfg.Admin.Enabled {
+		adminHandler := admin.NewHandler(cfg, log)
+		adminHandler.AddHealthCheck("database", func(ctx context.Context) (interface{}, error) {
+			return db.HealthDetails(ctx)
+		})
+		adminHandler.AddHealthCheck("leader", elector.HealthDetails)
+		adminHandler.Mount("/scheduler", sched.AdminRoutes(log))
+		adminHandler.Mount("/audit", auditLog.AdminRoutes(log))
+		adminHandler.Mount("/flags", featureFlags.AdminRoutes(log))
+
+		adminSrv := server.NewServer(adminHandler.Routes(), adminServerConfig(cfg), log)
+		app.MustRegister(adminSrv.Component("admin", cfg.Admin.GetAddr(), "database"))
+	}
+
+	// 7. Run until a shutdown signal or component failure
+	return app.Run(ctx)
+}
+
+// adminServerConfig derives the admin listener settings from the public
+// server ones: plain TCP HTTP/1.1 and a write timeout long enough for profiles
+func adminServerConfig(cfg *config.Config) *config.ServerConfig {
+	adminCfg := cfg.Server
+	adminCfg.TLS = config.TLSConfig{}
+	adminCfg.H2C = false
+	adminCfg.UnixSocket = ""
+	adminCfg.SystemdSocket = false
+	adminCfg.WriteTimeout = cfg.Admin.WriteTimeout
+	return &adminCfg
+}
+
+func setupRouter(cfg *config.Config, log logger.Logger, spec *openapi.Spec, clk clock.Clock, orderHandler *orders.Handler) *chi.Mux {
+	router := chi.NewRouter()
+
+	// Middleware
+	router.Use(middleware.RequestID)
+	router.Use(middleware.RealIP)
+	router.Use(middleware.Logger)
+	router.Use(middleware.Recoverer)
+	router.Use(metrics.Middleware)
+	router.Use(clock.Middleware(clk))
+	router.Use(request.MaxBodySize(cfg.Server.MaxBodySize))
+	router.Use(audit.Middleware)
+	router.Use(auth.NewTokens(&cfg.JWT).WithClock(clk).Middleware(log))
+
+	// Health check endpoint
+	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"ok"}`))
+	})
+
+	// API routes
+	router.Route("/api/v1", func(r chi.Router) {
+		// Validate requests, and in development responses, against the spec
+		if cfg.OpenAPI.ValidateRequests {
+			r.Use(spec.Middleware(router, log, cfg.OpenAPI.ValidateResponses && cfg.IsDevelopment()))
+		}
+
+		// API contract and docs
+		if cfg.OpenAPI.ServeDocs {
+			r.Get("/openapi.json", spec.JSONHandler(router))
+			r.Get("/docs", openapi.DocsHandler())
+			spec.Describe(http.MethodGet, "/openapi.json", openapi.Route{Summary: "OpenAPI document", Tags: []string{"meta"}})
+			spec.Describe(http.MethodGet, "/docs", openapi.Route{Summary: "API documentation page", Tags: []string{"meta"}})
+		}
+
+		// Module routes
+		r.Mount("/orders", orderHandler.Routes())
+		spec.AddTag("orders", "Order entry and lifecycle")
+		orderHandler.Describe(spec.Group("/orders"))
+	})
+
+	return router
+}
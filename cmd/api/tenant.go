@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+)
+
+// runTenantCreate adds a tenant, optionally with settings overrides
+func runTenantCreate(ctx context.Context, args []string) error {
+	fs := newFlagSet("tenant create", "")
+	id := fs.String("id", "", "tenant ID: lowercase letters, digits and hyphens (required)")
+	name := fs.String("name", "", "display name (required)")
+	settingsFile := fs.String("settings", "", `JSON file of overrides, e.g. {"trading": {"taker_fee_bps": 15}}`)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *id == "" || *name == "" {
+		return usageErrorf("--id and --name are required")
+	}
+
+	var settings json.RawMessage
+	if *settingsFile != "" {
+		data, err := os.ReadFile(*settingsFile)
+		if err != nil {
+			return usageErrorf("failed to read settings: %v", err)
+		}
+		settings = data
+	}
+
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	pool, err := connectDatabase(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	t, err := tenant.NewRegistry(pool, cfg).Create(ctx, *id, *name, settings)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	log.Info("tenant created", logger.String("tenant_id", t.ID), logger.String("name", t.Name))
+	return nil
+}
+
+// runTenantShow prints a tenant's effective trading configuration, i.e.
+// the defaults with its overrides applied
+func runTenantShow(ctx context.Context, args []string) error {
+	fs := newFlagSet("tenant show", "")
+	id := fs.String("id", "", "tenant ID (required)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *id == "" {
+		return usageErrorf("--id is required")
+	}
+
+	cfg, log, err := bootstrap()
+	if err != nil {
+		return err
+	}
+
+	pool, err := connectDatabase(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	registry := tenant.NewRegistry(pool, cfg)
+	t, err := registry.Get(ctx, *id)
+	if err != nil {
+		return err
+	}
+	effective, err := registry.Config(ctx, *id)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{
+		"tenant":  t,
+		"trading": effective.Trading,
+	})
+}
@@ -0,0 +1,115 @@
+// Command errcodes prints every registered error code with its default
+// message and translations. It backs `go generate` for docs/error-codes.md
+// and can emit JSON for the frontend.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+
+	// Packages that register their own error codes
+	_ "github.com/F1sssss/Perfect_Trade/internal/instruments"
+	_ "github.com/F1sssss/Perfect_Trade/internal/orders"
+	_ "github.com/F1sssss/Perfect_Trade/internal/shared/decimal"
+	_ "github.com/F1sssss/Perfect_Trade/internal/shared/pagination"
+	_ "github.com/F1sssss/Perfect_Trade/internal/shared/request"
+	_ "github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	format := flag.String("format", "markdown", "output format: markdown or json")
+	output := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	registry := apperrors.DefaultRegistry()
+	switch *format {
+	case "markdown":
+		return writeMarkdown(w, registry)
+	case "json":
+		return writeJSON(w, registry)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
+
+// codeEntry is the JSON representation of a registered code
+type codeEntry struct {
+	Code         string            `json:"code"`
+	Status       int               `json:"status"`
+	Message      string            `json:"message"`
+	Description  string            `json:"description,omitempty"`
+	Translations map[string]string `json:"translations,omitempty"`
+}
+
+func collect(registry *apperrors.Registry) []codeEntry {
+	langs := registry.Languages()[1:] // skip the base language
+
+	defs := registry.Codes()
+	entries := make([]codeEntry, 0, len(defs))
+	for _, def := range defs {
+		entry := codeEntry{
+			Code:        def.Code,
+			Status:      apperrors.StatusOf(def.Category),
+			Message:     def.Message,
+			Description: def.Description,
+		}
+		for _, lang := range langs {
+			if msg, ok := registry.Message(def.Code, lang, nil); ok && msg != def.Message {
+				if entry.Translations == nil {
+					entry.Translations = make(map[string]string)
+				}
+				entry.Translations[lang.String()] = msg
+			}
+		}
+		if def.Status != 0 {
+			entry.Status = def.Status
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func writeJSON(w io.Writer, registry *apperrors.Registry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(collect(registry))
+}
+
+func writeMarkdown(w io.Writer, registry *apperrors.Registry) error {
+	var b strings.Builder
+	b.WriteString("# Error codes\n\n")
+	b.WriteString("<!-- Code generated by cmd/errcodes. DO NOT EDIT. -->\n\n")
+	b.WriteString("Every error response carries one of these codes in its `code` field.\n")
+	b.WriteString("Messages are localized according to the `Accept-Language` header.\n\n")
+	b.WriteString("| Code | Status | Message | Description |\n")
+	b.WriteString("|------|--------|---------|-------------|\n")
+	for _, e := range collect(registry) {
+		fmt.Fprintf(&b, "| `%s` | %d | %s | %s |\n", e.Code, e.Status, e.Message, e.Description)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
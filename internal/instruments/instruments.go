@@ -0,0 +1,383 @@
+package instruments
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/cache"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/decimal"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// Asset classes
+const (
+	AssetEquity = "equity"
+	AssetETF    = "etf"
+	AssetFX     = "fx"
+	AssetCrypto = "crypto"
+	AssetFuture = "future"
+	AssetBond   = "bond"
+)
+
+// Statuses
+const (
+	StatusActive   = "active"
+	StatusHalted   = "halted"
+	StatusDelisted = "delisted"
+)
+
+// Error codes of instrument CSV imports
+const (
+	CodeCSVEmpty         = "INSTRUMENT_CSV_EMPTY"
+	CodeCSVMalformed     = "INSTRUMENT_CSV_MALFORMED"
+	CodeCSVMissingColumn = "INSTRUMENT_CSV_MISSING_COLUMN"
+	CodeDuplicateSymbol  = "INSTRUMENT_DUPLICATE_SYMBOL"
+)
+
+func init() {
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeCSVEmpty,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The instrument file is empty",
+		Description: "An instrument import without a header row",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeCSVMalformed,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The instrument file is not valid CSV at line {line}",
+		Description: "An instrument import with broken quoting or a wrong number of fields",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeCSVMissingColumn,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The instrument file has no {column} column",
+		Description: "An instrument import whose header lacks a required column",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeDuplicateSymbol,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "{symbol} is listed on line {first} and again on line {line}",
+		Description: "An instrument import that lists a symbol twice",
+	})
+}
+
+// Instrument is a tradable security
+type Instrument struct {
+	Symbol        string          `json:"symbol"`
+	Name          string          `json:"name"`
+	AssetClass    string          `json:"asset_class"`
+	Currency      string          `json:"currency"`
+	TickSize      decimal.Decimal `json:"tick_size"`
+	LotSize       decimal.Decimal `json:"lot_size"`
+	PriceScale    int             `json:"price_scale"`
+	QuantityScale int             `json:"quantity_scale"`
+	Status        string          `json:"status"`
+}
+
+// Validate checks the instrument fields
+func (i *Instrument) Validate() error {
+	ve := apperrors.NewValidationError()
+
+	if i.Symbol == "" {
+		ve.AddCode("symbol", apperrors.CodeFieldRequired, nil)
+	} else if len(i.Symbol) > 32 {
+		ve.AddCode("symbol", apperrors.CodeFieldTooLong, map[string]interface{}{"max": 32})
+	}
+	if i.Name == "" {
+		ve.AddCode("name", apperrors.CodeFieldRequired, nil)
+	}
+	switch i.AssetClass {
+	case AssetEquity, AssetETF, AssetFX, AssetCrypto, AssetFuture, AssetBond:
+	default:
+		ve.AddCode("asset_class", apperrors.CodeFieldInvalid, nil)
+	}
+	if len(i.Currency) != 3 || strings.ToUpper(i.Currency) != i.Currency {
+		ve.AddCode("currency", apperrors.CodeFieldInvalid, nil)
+	}
+	decimal.CheckPositive(ve, "tick_size", i.TickSize)
+	decimal.CheckPositive(ve, "lot_size", i.LotSize)
+	if i.PriceScale < 0 || i.PriceScale > 18 {
+		ve.AddCode("price_scale", apperrors.CodeFieldRange, map[string]interface{}{"min": 0, "max": 18})
+	}
+	if i.QuantityScale < 0 || i.QuantityScale > 18 {
+		ve.AddCode("quantity_scale", apperrors.CodeFieldRange, map[string]interface{}{"min": 0, "max": 18})
+	}
+	switch i.Status {
+	case StatusActive, StatusHalted, StatusDelisted:
+	default:
+		ve.AddCode("status", apperrors.CodeFieldInvalid, nil)
+	}
+
+	return ve.ErrOrNil()
+}
+
+// ValidatePrice records field errors on ve unless price fits the
+// instrument's price scale and tick size
+func (i *Instrument) ValidatePrice(ve *apperrors.ValidationError, field string, price decimal.Decimal) {
+	if decimal.CheckScale(ve, field, price, int32(i.PriceScale)) {
+		decimal.CheckTick(ve, field, price, i.TickSize)
+	}
+}
+
+// ValidateQuantity records field errors on ve unless quantity fits the
+// instrument's quantity scale and lot size
+func (i *Instrument) ValidateQuantity(ve *apperrors.ValidationError, field string, quantity decimal.Decimal) {
+	if decimal.CheckScale(ve, field, quantity, int32(i.QuantityScale)) {
+		decimal.CheckLot(ve, field, quantity, i.LotSize)
+	}
+}
+
+// CacheName addresses the instrument cache in invalidation messages
+const CacheName = "instruments"
+
+// NewCache creates the cache used by Repository.WithCache. Instruments
+// change rarely and are read on every order.
+func NewCache() *cache.Cache[string, *Instrument] {
+	return cache.New[string, *Instrument](CacheName, cache.Options{
+		Size:        10_000,
+		TTL:         10 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+	})
+}
+
+// Repository stores instruments
+type Repository struct {
+	pool  *pgxpool.Pool
+	db    *database.Cluster
+	cache *cache.Cache[string, *Instrument]
+}
+
+// NewRepository creates a new instrument repository
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// WithCache serves Get from c. Attach c to the cache invalidator so
+// writes from any instance evict it.
+func (r *Repository) WithCache(c *cache.Cache[string, *Instrument]) *Repository {
+	r.cache = c
+	return r
+}
+
+// WithCluster sends reads outside a transaction to db's replicas
+func (r *Repository) WithCluster(db *database.Cluster) *Repository {
+	r.db = db
+	return r
+}
+
+// reader returns the querier for reads
+func (r *Repository) reader(ctx context.Context) database.Querier {
+	if r.db == nil {
+		return database.GetQuerier(ctx, r.pool)
+	}
+	return r.db.Reader(ctx)
+}
+
+// Get returns an instrument by symbol. Inside a transaction it reads the
+// database directly, so the transaction's own writes are visible.
+func (r *Repository) Get(ctx context.Context, symbol string) (*Instrument, error) {
+	if r.cache == nil || database.GetTx(ctx) != nil {
+		return r.get(ctx, symbol)
+	}
+	inst, err := r.cache.Get(ctx, symbol, func(ctx context.Context) (*Instrument, error) {
+		return r.get(ctx, symbol)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Callers may modify their copy; the cached one is shared
+	clone := *inst
+	return &clone, nil
+}
+
+func (r *Repository) get(ctx context.Context, symbol string) (*Instrument, error) {
+	i := &Instrument{}
+	err := r.reader(ctx).QueryRow(ctx,
+		`SELECT symbol, name, asset_class, currency, tick_size, lot_size,
+		        price_scale, quantity_scale, status
+		 FROM instruments WHERE symbol = $1`,
+		symbol,
+	).Scan(&i.Symbol, &i.Name, &i.AssetClass, &i.Currency, &i.TickSize, &i.LotSize,
+		&i.PriceScale, &i.QuantityScale, &i.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Wrapf(apperrors.ErrNotFound, "instrument %s", symbol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get instrument %s: %v", apperrors.ErrDatabase, symbol, err)
+	}
+	return i, nil
+}
+
+// Upsert inserts an instrument or updates the existing one with the same
+// symbol. It reports whether a new row was inserted. Cached copies are
+// evicted on every instance once the change commits.
+func (r *Repository) Upsert(ctx context.Context, i *Instrument) (bool, error) {
+	inserted, err := r.upsert(ctx, i)
+	if err != nil {
+		return false, err
+	}
+	if err := cache.Invalidate(ctx, r.pool, CacheName, i.Symbol); err != nil {
+		return false, err
+	}
+	return inserted, nil
+}
+
+func (r *Repository) upsert(ctx context.Context, i *Instrument) (bool, error) {
+	var inserted bool
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx,
+		`INSERT INTO instruments (symbol, name, asset_class, currency, tick_size, lot_size,
+		                          price_scale, quantity_scale, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (symbol) DO UPDATE SET
+		     name           = EXCLUDED.name,
+		     asset_class    = EXCLUDED.asset_class,
+		     currency       = EXCLUDED.currency,
+		     tick_size      = EXCLUDED.tick_size,
+		     lot_size       = EXCLUDED.lot_size,
+		     price_scale    = EXCLUDED.price_scale,
+		     quantity_scale = EXCLUDED.quantity_scale,
+		     status         = EXCLUDED.status,
+		     updated_at     = now()
+		 RETURNING (xmax = 0)`,
+		i.Symbol, i.Name, i.AssetClass, i.Currency, i.TickSize, i.LotSize,
+		i.PriceScale, i.QuantityScale, i.Status,
+	).Scan(&inserted)
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to upsert instrument %s: %v", apperrors.ErrDatabase, i.Symbol, err)
+	}
+	return inserted, nil
+}
+
+// ImportResult summarizes a CSV import
+type ImportResult struct {
+	Inserted int `json:"inserted"`
+	Updated  int `json:"updated"`
+}
+
+// csvColumns are the accepted CSV header names; status is optional
+var csvColumns = []string{
+	"symbol", "name", "asset_class", "currency", "tick_size", "lot_size",
+	"price_scale", "quantity_scale", "status",
+}
+
+// ParseCSV reads instruments from CSV with a header row. Every row is
+// validated; errors are reported with their line number.
+func ParseCSV(r io.Reader) ([]*Instrument, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, apperrors.New(apperrors.ErrInvalidInput, CodeCSVEmpty, "")
+		}
+		return nil, apperrors.New(apperrors.ErrInvalidInput, CodeCSVMalformed, "").
+			WithMeta("line", 1).
+			WithCause(err)
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := index[name]; !ok && name != "status" {
+			return nil, apperrors.New(apperrors.ErrInvalidInput, CodeCSVMissingColumn, "").
+				WithMeta("column", name)
+		}
+	}
+
+	var result []*Instrument
+	seen := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			return nil, apperrors.New(apperrors.ErrInvalidInput, CodeCSVMalformed, "").
+				WithMeta("line", line).
+				WithCause(err)
+		}
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		inst := &Instrument{
+			Symbol:     strings.ToUpper(field("symbol")),
+			Name:       field("name"),
+			AssetClass: strings.ToLower(field("asset_class")),
+			Currency:   field("currency"),
+			Status:     strings.ToLower(field("status")),
+		}
+		// Unparseable sizes stay zero and fail validation
+		inst.TickSize, _ = decimal.Parse(field("tick_size"))
+		inst.LotSize, _ = decimal.Parse(field("lot_size"))
+		if inst.Status == "" {
+			inst.Status = StatusActive
+		}
+		if inst.PriceScale, err = strconv.Atoi(field("price_scale")); err != nil {
+			inst.PriceScale = -1
+		}
+		if inst.QuantityScale, err = strconv.Atoi(field("quantity_scale")); err != nil {
+			inst.QuantityScale = -1
+		}
+
+		if err := inst.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if prev, ok := seen[inst.Symbol]; ok {
+			return nil, apperrors.New(apperrors.ErrInvalidInput, CodeDuplicateSymbol, "").
+				WithMeta("symbol", inst.Symbol).
+				WithMeta("first", prev).
+				WithMeta("line", line)
+		}
+		seen[inst.Symbol] = line
+		result = append(result, inst)
+	}
+	return result, nil
+}
+
+// Import upserts all instruments inside one transaction so a failed
+// import leaves the table unchanged. The whole instrument cache is
+// evicted on commit.
+func (r *Repository) Import(ctx context.Context, list []*Instrument) (*ImportResult, error) {
+	result := &ImportResult{}
+	tm := database.NewPostgresTransactionManager(r.pool)
+	_, err := tm.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		if err := cache.Invalidate(ctx, r.pool, CacheName); err != nil {
+			return nil, err
+		}
+		for _, inst := range list {
+			inserted, err := r.upsert(ctx, inst)
+			if err != nil {
+				return nil, err
+			}
+			if inserted {
+				result.Inserted++
+			} else {
+				result.Updated++
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,204 @@
+package orders
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/id"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/openapi"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/pagination"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/request"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+)
+
+// Handler serves the orders API
+type Handler struct {
+	service   *Service
+	paginator *pagination.Paginator
+	log       logger.Logger
+}
+
+// NewHandler creates an orders API handler
+func NewHandler(service *Service, paginator *pagination.Paginator, log logger.Logger) *Handler {
+	return &Handler{service: service, paginator: paginator, log: log}
+}
+
+// Routes returns the orders routes, for mounting at /orders:
+//
+//	POST  /              submit an order
+//	GET   /              the user's orders, newest first
+//	GET   /{id}          one order
+//	GET   /{id}/events   an order's history
+//	PATCH /{id}          amend price or quantity
+//	POST  /{id}/cancel   cancel an open order
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+	r.Use(tenant.Require(h.log))
+
+	r.Post("/", h.create)
+	r.Get("/", h.list)
+	r.Get("/{id}", h.get)
+	r.Get("/{id}/events", h.events)
+	r.Patch("/{id}", h.amend)
+	r.Post("/{id}/cancel", h.cancel)
+
+	return r
+}
+
+// Describe documents the routes for the API contract
+func (h *Handler) Describe(g *openapi.Group) {
+	idParam := []openapi.Parameter{
+		{Name: "id", In: "path", Required: true, Schema: id.ID(0).OpenAPISchema()},
+	}
+	tags := []string{"orders"}
+
+	g.Describe(http.MethodPost, "/", openapi.Route{
+		Summary:     "Submit an order",
+		Description: "Creates an order in status new. Requires the trader or admin role.",
+		Tags:        tags,
+		Request:     CreateOrderRequest{},
+		Responses:   map[int]interface{}{http.StatusCreated: Order{}},
+	})
+	g.Describe(http.MethodGet, "/", openapi.Route{
+		Summary:   "List orders",
+		Tags:      tags,
+		Params:    listSpec.Parameters(),
+		Responses: map[int]interface{}{http.StatusOK: pagination.Page[*Order]{}},
+	})
+	g.Describe(http.MethodGet, "/{id}", openapi.Route{
+		Summary:   "Get an order",
+		Tags:      tags,
+		Params:    idParam,
+		Responses: map[int]interface{}{http.StatusOK: Order{}},
+	})
+	g.Describe(http.MethodGet, "/{id}/events", openapi.Route{
+		Summary:   "Get an order's history",
+		Tags:      tags,
+		Params:    idParam,
+		Responses: map[int]interface{}{http.StatusOK: []Event{}},
+	})
+	g.Describe(http.MethodPatch, "/{id}", openapi.Route{
+		Summary:     "Amend an order",
+		Description: "Changes the price or quantity of an open limit order",
+		Tags:        tags,
+		Params:      idParam,
+		Request:     AmendOrderRequest{},
+		Responses:   map[int]interface{}{http.StatusOK: Order{}},
+	})
+	g.Describe(http.MethodPost, "/{id}/cancel", openapi.Route{
+		Summary:   "Cancel an order",
+		Tags:      tags,
+		Params:    idParam,
+		Responses: map[int]interface{}{http.StatusOK: Order{}},
+	})
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	var req CreateOrderRequest
+	if err := request.DecodeJSON(r, &req); err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	o, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	writeJSON(w, http.StatusCreated, o)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	q, err := h.paginator.Parse(r, listSpec)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	page, err := h.service.List(r.Context(), q)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	orderID, err := orderIDParam(r)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	o, err := h.service.Get(r.Context(), orderID)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (h *Handler) events(w http.ResponseWriter, r *http.Request) {
+	orderID, err := orderIDParam(r)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	events, err := h.service.Events(r.Context(), orderID)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (h *Handler) amend(w http.ResponseWriter, r *http.Request) {
+	orderID, err := orderIDParam(r)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	var req AmendOrderRequest
+	if err := request.DecodeJSON(r, &req); err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	o, err := h.service.Amend(r.Context(), orderID, &req)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (h *Handler) cancel(w http.ResponseWriter, r *http.Request) {
+	orderID, err := orderIDParam(r)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	o, err := h.service.Cancel(r.Context(), orderID)
+	if err != nil {
+		apperrors.WriteError(w, r, err, h.log)
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+// orderIDParam reads the {id} path parameter. A malformed ID can't name
+// an existing order, so it is reported as not found.
+func orderIDParam(r *http.Request) (id.ID, error) {
+	raw := chi.URLParam(r, "id")
+	orderID, err := id.Parse(raw)
+	if err != nil {
+		return 0, apperrors.Wrapf(apperrors.ErrNotFound, "order %s", raw)
+	}
+	return orderID, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,226 @@
+package orders
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/decimal"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/id"
+)
+
+// Sides
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// Order types
+const (
+	TypeMarket = "market"
+	TypeLimit  = "limit"
+)
+
+// Time in force
+const (
+	TimeInForceGTC = "gtc" // good till cancelled
+	TimeInForceDay = "day" // until the end of the trading day
+	TimeInForceGTD = "gtd" // good till expires_at
+	TimeInForceIOC = "ioc" // immediate or cancel
+	TimeInForceFOK = "fok" // fill or kill
+)
+
+// Status is an order's position in its lifecycle
+type Status string
+
+// Statuses
+const (
+	StatusNew             Status = "new"
+	StatusAccepted        Status = "accepted"
+	StatusPartiallyFilled Status = "partially_filled"
+	StatusFilled          Status = "filled"
+	StatusCancelled       Status = "cancelled"
+	StatusRejected        Status = "rejected"
+	StatusExpired         Status = "expired"
+)
+
+// transitions lists the statuses each status may move to. Statuses
+// without an entry are terminal. A partially filled order stays
+// partially filled on further partial fills.
+var transitions = map[Status][]Status{
+	StatusNew:             {StatusAccepted, StatusRejected, StatusCancelled, StatusExpired},
+	StatusAccepted:        {StatusPartiallyFilled, StatusFilled, StatusCancelled, StatusExpired},
+	StatusPartiallyFilled: {StatusPartiallyFilled, StatusFilled, StatusCancelled, StatusExpired},
+}
+
+// CanTransition reports whether an order may move from s to next
+func (s Status) CanTransition(next Status) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether an order in s can still trade, be amended or
+// be cancelled
+func (s Status) IsOpen() bool {
+	return len(transitions[s]) > 0
+}
+
+// openStatuses are the statuses counted against the open order limit
+var openStatuses = []string{string(StatusNew), string(StatusAccepted), string(StatusPartiallyFilled)}
+
+// Error codes returned by this package
+const (
+	CodeOrderNotOpen          = "ORDER_NOT_OPEN"
+	CodeInvalidTransition     = "ORDER_INVALID_TRANSITION"
+	CodeInstrumentNotTradable = "INSTRUMENT_NOT_TRADABLE"
+	CodeNotionalExceeded      = "ORDER_NOTIONAL_EXCEEDED"
+	CodeOpenOrderLimit        = "OPEN_ORDER_LIMIT"
+)
+
+func init() {
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeOrderNotOpen,
+		Category:    apperrors.ErrBusinessRule,
+		Status:      http.StatusConflict,
+		Message:     "The order is {status} and can no longer be changed",
+		Description: "Cancel or amend of an order that is filled, cancelled, rejected or expired",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeInvalidTransition,
+		Category:    apperrors.ErrBusinessRule,
+		Status:      http.StatusConflict,
+		Message:     "The order cannot move from {from} to {to}",
+		Description: "A state change the order lifecycle does not allow",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeInstrumentNotTradable,
+		Category:    apperrors.ErrBusinessRule,
+		Message:     "{symbol} is not tradable",
+		Description: "The instrument is halted or delisted, or not enabled for the tenant",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeNotionalExceeded,
+		Category:    apperrors.ErrBusinessRule,
+		Message:     "The order value must not exceed {max}",
+		Description: "Price times quantity is above the tenant's maximum order notional",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeOpenOrderLimit,
+		Category:    apperrors.ErrBusinessRule,
+		Message:     "No more than {max} orders may be open at once",
+		Description: "The user has reached the tenant's open order limit",
+	})
+}
+
+// averagePriceScale is the precision of volume-weighted fill prices
+const averagePriceScale = 10
+
+// Order is an instruction to buy or sell an instrument
+type Order struct {
+	ID             id.ID            `json:"id"`
+	ClientOrderID  string           `json:"client_order_id,omitempty"`
+	UserID         string           `json:"user_id"`
+	Symbol         string           `json:"symbol"`
+	Side           string           `json:"side" enum:"buy,sell"`
+	Type           string           `json:"type" enum:"market,limit"`
+	TimeInForce    string           `json:"time_in_force" enum:"gtc,day,gtd,ioc,fok"`
+	Price          *decimal.Decimal `json:"price,omitempty" doc:"Limit price; absent for market orders"`
+	Quantity       decimal.Decimal  `json:"quantity"`
+	FilledQuantity decimal.Decimal  `json:"filled_quantity"`
+	AveragePrice   *decimal.Decimal `json:"average_price,omitempty" doc:"Volume-weighted fill price"`
+	Status         Status           `json:"status" enum:"new,accepted,partially_filled,filled,cancelled,rejected,expired"`
+	RejectReason   string           `json:"reject_reason,omitempty"`
+	ExpiresAt      *time.Time       `json:"expires_at,omitempty"`
+	Version        int              `json:"version" doc:"Incremented on every change"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// Remaining returns the quantity not yet filled
+func (o *Order) Remaining() decimal.Decimal {
+	return o.Quantity.Sub(o.FilledQuantity)
+}
+
+// transition moves the order to next, or fails if the lifecycle forbids
+// it
+func (o *Order) transition(next Status, now time.Time) error {
+	if !o.Status.CanTransition(next) {
+		return apperrors.Newf(apperrors.ErrBusinessRule, CodeInvalidTransition,
+			"order %s cannot move from %s to %s", o.ID, o.Status, next).
+			WithMeta("from", o.Status).
+			WithMeta("to", next)
+	}
+	o.Status = next
+	o.UpdatedAt = now
+	return nil
+}
+
+// requireOpen fails for orders that can no longer change
+func (o *Order) requireOpen() error {
+	if !o.Status.IsOpen() {
+		return apperrors.Newf(apperrors.ErrBusinessRule, CodeOrderNotOpen,
+			"order %s is %s", o.ID, o.Status).
+			WithMeta("status", o.Status)
+	}
+	return nil
+}
+
+// fill records an execution of quantity at price
+func (o *Order) fill(quantity, price decimal.Decimal, now time.Time) error {
+	if quantity.Sign() <= 0 || price.Sign() <= 0 {
+		return apperrors.Wrapf(apperrors.ErrInvalidInput, "fill of %s at %s", quantity, price)
+	}
+	if quantity.Cmp(o.Remaining()) > 0 {
+		return apperrors.Wrapf(apperrors.ErrInvalidInput,
+			"fill of %s exceeds remaining quantity %s of order %s", quantity, o.Remaining(), o.ID)
+	}
+
+	next := StatusPartiallyFilled
+	if quantity.Equal(o.Remaining()) {
+		next = StatusFilled
+	}
+	if err := o.transition(next, now); err != nil {
+		return err
+	}
+
+	// average = (average × filled + price × quantity) / (filled + quantity)
+	value := price.Mul(quantity)
+	if o.AveragePrice != nil {
+		value = value.Add(o.AveragePrice.Mul(o.FilledQuantity))
+	}
+	o.FilledQuantity = o.FilledQuantity.Add(quantity)
+	average, err := value.Div(o.FilledQuantity, averagePriceScale, decimal.HalfEven)
+	if err != nil {
+		return apperrors.Wrapf(apperrors.ErrInternal, "average price of order %s: %v", o.ID, err)
+	}
+	average = average.Normalize()
+	o.AveragePrice = &average
+	return nil
+}
+
+// Event types
+const (
+	EventCreated   = "created"
+	EventAccepted  = "accepted"
+	EventRejected  = "rejected"
+	EventAmended   = "amended"
+	EventFilled    = "filled"
+	EventCancelled = "cancelled"
+	EventExpired   = "expired"
+)
+
+// Event is one entry in an order's history
+type Event struct {
+	ID         id.ID           `json:"id"`
+	OrderID    id.ID           `json:"order_id"`
+	Type       string          `json:"type" enum:"created,accepted,rejected,amended,filled,cancelled,expired"`
+	FromStatus Status          `json:"from_status,omitempty"`
+	ToStatus   Status          `json:"to_status"`
+	Data       json.RawMessage `json:"data,omitempty" doc:"Event details, e.g. fill quantity and price or amended fields"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
@@ -0,0 +1,253 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/id"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/pagination"
+)
+
+const orderColumns = `id, COALESCE(client_order_id, ''), user_id, symbol, side, type, time_in_force,
+	price, quantity, filled_quantity, average_price, status, reject_reason, expires_at,
+	version, created_at, updated_at`
+
+// listSpec is the allow-list of GET /orders
+var listSpec = &pagination.Spec{
+	Name: "orders",
+	Fields: []pagination.Field{
+		{Name: "id", Type: pagination.Int, Sortable: true},
+		{Name: "created_at", Type: pagination.Time, Sortable: true, Ops: []pagination.Op{pagination.Gte, pagination.Lt}},
+		{Name: "symbol", Type: pagination.String, Ops: []pagination.Op{pagination.Eq, pagination.In}},
+		{Name: "side", Type: pagination.String, Ops: []pagination.Op{pagination.Eq}, Values: []string{SideBuy, SideSell}},
+		{Name: "type", Type: pagination.String, Ops: []pagination.Op{pagination.Eq}, Values: []string{TypeMarket, TypeLimit}},
+		{
+			Name: "status", Type: pagination.String,
+			Ops: []pagination.Op{pagination.Eq, pagination.Ne, pagination.In},
+			Values: []string{
+				string(StatusNew), string(StatusAccepted), string(StatusPartiallyFilled), string(StatusFilled),
+				string(StatusCancelled), string(StatusRejected), string(StatusExpired),
+			},
+		},
+		{Name: "client_order_id", Type: pagination.String, Ops: []pagination.Op{pagination.Eq}},
+	},
+	DefaultSort: "-created_at",
+	Unique:      "id",
+}
+
+// Repository stores orders and their events. Writes and locking reads go
+// to the primary; other reads may be served by a replica.
+type Repository struct {
+	db *database.Cluster
+}
+
+// NewRepository creates a new order repository
+func NewRepository(db *database.Cluster) *Repository {
+	return &Repository{db: db}
+}
+
+// Insert stores a new order. A client order ID the user already used
+// fails with ErrAlreadyExists.
+func (r *Repository) Insert(ctx context.Context, o *Order) error {
+	var clientOrderID *string
+	if o.ClientOrderID != "" {
+		clientOrderID = &o.ClientOrderID
+	}
+	_, err := r.db.Writer(ctx).Exec(ctx,
+		`INSERT INTO orders (id, client_order_id, user_id, symbol, side, type, time_in_force,
+		                     price, quantity, filled_quantity, average_price, status, reject_reason,
+		                     expires_at, version, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
+		o.ID, clientOrderID, o.UserID, o.Symbol, o.Side, o.Type, o.TimeInForce,
+		o.Price, o.Quantity, o.FilledQuantity, o.AveragePrice, o.Status, o.RejectReason,
+		o.ExpiresAt, o.Version, o.CreatedAt, o.UpdatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return apperrors.Wrapf(apperrors.ErrAlreadyExists, "order with client_order_id %s", o.ClientOrderID)
+		}
+		return fmt.Errorf("%w: failed to insert order: %v", apperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// Get returns one of a user's orders
+func (r *Repository) Get(ctx context.Context, orderID id.ID, userID string) (*Order, error) {
+	return r.get(ctx, orderID, userID, "")
+}
+
+// GetForUpdate returns one of a user's orders and locks it until the
+// transaction in ctx ends. An empty userID matches any user's order.
+func (r *Repository) GetForUpdate(ctx context.Context, orderID id.ID, userID string) (*Order, error) {
+	return r.get(ctx, orderID, userID, " FOR UPDATE")
+}
+
+func (r *Repository) get(ctx context.Context, orderID id.ID, userID, lock string) (*Order, error) {
+	q := r.db.Reader(ctx)
+	if lock != "" {
+		q = r.db.Writer(ctx)
+	}
+	row := q.QueryRow(ctx,
+		`SELECT `+orderColumns+` FROM orders
+		 WHERE id = $1 AND ($2 = '' OR user_id::text = $2)`+lock,
+		orderID, userID,
+	)
+	o, err := scanOrder(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Wrapf(apperrors.ErrNotFound, "order %s", orderID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get order %s: %v", apperrors.ErrDatabase, orderID, err)
+	}
+	return o, nil
+}
+
+// Update saves a changed order and bumps its version. The order must
+// have been read with GetForUpdate in the same transaction.
+func (r *Repository) Update(ctx context.Context, o *Order) error {
+	err := r.db.Writer(ctx).QueryRow(ctx,
+		`UPDATE orders SET
+		     price           = $2,
+		     quantity        = $3,
+		     filled_quantity = $4,
+		     average_price   = $5,
+		     status          = $6,
+		     reject_reason   = $7,
+		     updated_at      = $8,
+		     version         = version + 1
+		 WHERE id = $1
+		 RETURNING version`,
+		o.ID, o.Price, o.Quantity, o.FilledQuantity, o.AveragePrice, o.Status, o.RejectReason, o.UpdatedAt,
+	).Scan(&o.Version)
+	if err != nil {
+		return fmt.Errorf("%w: failed to update order %s: %v", apperrors.ErrDatabase, o.ID, err)
+	}
+	return nil
+}
+
+// List returns a page of a user's orders
+func (r *Repository) List(ctx context.Context, userID string, q *pagination.Query) (*pagination.Page[*Order], error) {
+	sql, args := q.SQL(`SELECT `+orderColumns+` FROM orders`, []string{"user_id = $1"}, userID)
+	rows, err := r.db.Reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list orders: %v", apperrors.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	var list []*Order
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to scan order: %v", apperrors.ErrDatabase, err)
+		}
+		list = append(list, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: failed to list orders: %v", apperrors.ErrDatabase, err)
+	}
+
+	return pagination.NewPage(q, list, func(o *Order, field string) any {
+		if field == "created_at" {
+			return o.CreatedAt
+		}
+		return int64(o.ID)
+	})
+}
+
+// openOrdersLockKey, with the user's hash, serializes the open order
+// limit check of one user
+const openOrdersLockKey int32 = 7_414_050
+
+// LockUser holds back other transactions that lock the same user until
+// the transaction in ctx ends
+func (r *Repository) LockUser(ctx context.Context, userID string) error {
+	_, err := r.db.Writer(ctx).Exec(ctx,
+		"SELECT pg_advisory_xact_lock($1, hashtext($2))", openOrdersLockKey, userID)
+	if err != nil {
+		return fmt.Errorf("%w: failed to lock user %s: %v", apperrors.ErrDatabase, userID, err)
+	}
+	return nil
+}
+
+// CountOpen returns how many of a user's orders are open
+func (r *Repository) CountOpen(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.db.Writer(ctx).QueryRow(ctx,
+		`SELECT count(*) FROM orders WHERE user_id = $1 AND status = ANY($2)`,
+		userID, openStatuses,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to count open orders: %v", apperrors.ErrDatabase, err)
+	}
+	return n, nil
+}
+
+// InsertEvent appends to an order's history
+func (r *Repository) InsertEvent(ctx context.Context, e *Event) error {
+	data := e.Data
+	if data == nil {
+		data = []byte("{}")
+	}
+	_, err := r.db.Writer(ctx).Exec(ctx,
+		`INSERT INTO order_events (id, order_id, type, from_status, to_status, data, occurred_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		e.ID, e.OrderID, e.Type, e.FromStatus, e.ToStatus, data, e.OccurredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: failed to insert order event: %v", apperrors.ErrDatabase, err)
+	}
+	return nil
+}
+
+// Events returns an order's history, oldest first
+func (r *Repository) Events(ctx context.Context, orderID id.ID) ([]Event, error) {
+	rows, err := r.db.Reader(ctx).Query(ctx,
+		`SELECT id, order_id, type, from_status, to_status, data, occurred_at
+		 FROM order_events WHERE order_id = $1 ORDER BY id`,
+		orderID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to list order events: %v", apperrors.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		var data []byte
+		if err := rows.Scan(&e.ID, &e.OrderID, &e.Type, &e.FromStatus, &e.ToStatus, &data, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("%w: failed to scan order event: %v", apperrors.ErrDatabase, err)
+		}
+		if string(data) != "{}" {
+			e.Data = data
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: failed to list order events: %v", apperrors.ErrDatabase, err)
+	}
+	return events, nil
+}
+
+func scanOrder(row pgx.Row) (*Order, error) {
+	o := &Order{}
+	err := row.Scan(&o.ID, &o.ClientOrderID, &o.UserID, &o.Symbol, &o.Side, &o.Type, &o.TimeInForce,
+		&o.Price, &o.Quantity, &o.FilledQuantity, &o.AveragePrice, &o.Status, &o.RejectReason, &o.ExpiresAt,
+		&o.Version, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return apperrors.As(err, &pgErr) && pgErr.Code == "23505"
+}
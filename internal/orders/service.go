@@ -0,0 +1,512 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/instruments"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/audit"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/auth"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/clock"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/decimal"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/id"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/pagination"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+	"github.com/F1sssss/Perfect_Trade/internal/users"
+)
+
+// maxClientOrderIDLength matches the orders table check
+const maxClientOrderIDLength = 64
+
+// CreateOrderRequest is the body of POST /orders
+type CreateOrderRequest struct {
+	ClientOrderID string           `json:"client_order_id,omitempty" maxLength:"64" doc:"Caller's ID; a repeated one is rejected"`
+	Symbol        string           `json:"symbol" maxLength:"32" example:"AAPL"`
+	Side          string           `json:"side" enum:"buy,sell"`
+	Type          string           `json:"type" enum:"market,limit"`
+	TimeInForce   string           `json:"time_in_force,omitempty" enum:"gtc,day,gtd,ioc,fok" doc:"Defaults to gtc for limit and ioc for market orders"`
+	Price         *decimal.Decimal `json:"price,omitempty" doc:"Required for limit orders, not allowed for market orders"`
+	Quantity      decimal.Decimal  `json:"quantity"`
+	ExpiresAt     *time.Time       `json:"expires_at,omitempty" doc:"Required for gtd, not allowed otherwise"`
+}
+
+// AmendOrderRequest is the body of PATCH /orders/{id}. Fields left out
+// keep their value.
+type AmendOrderRequest struct {
+	Price    *decimal.Decimal `json:"price,omitempty"`
+	Quantity *decimal.Decimal `json:"quantity,omitempty" doc:"New total quantity; must exceed the filled quantity"`
+}
+
+// Service runs the order lifecycle. Every change locks the order, moves
+// it through the state machine and appends to its history in one
+// transaction.
+type Service struct {
+	repo        *Repository
+	db          *database.Cluster
+	tm          *database.PostgresTransactionManager
+	instruments *instruments.Repository
+	tenants     *tenant.Registry
+	audit       *audit.Log
+	ids         *id.Generator
+}
+
+// NewService creates an order service
+func NewService(db *database.Cluster, instrumentRepo *instruments.Repository, tenants *tenant.Registry, auditLog *audit.Log, ids *id.Generator) *Service {
+	return &Service{
+		repo:        NewRepository(db),
+		db:          db,
+		tm:          database.NewPostgresTransactionManager(db.Primary()),
+		instruments: instrumentRepo,
+		tenants:     tenants,
+		audit:       auditLog,
+		ids:         ids,
+	}
+}
+
+// Create validates and stores a new order for the authenticated user.
+// Orders start as new; the matching engine accepts or rejects them.
+func (s *Service) Create(ctx context.Context, req *CreateOrderRequest) (*Order, error) {
+	claims, err := trader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := s.tenants.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Symbol = strings.ToUpper(strings.TrimSpace(req.Symbol))
+	var inst *instruments.Instrument
+	if req.Symbol != "" {
+		inst, err = s.instruments.Get(ctx, req.Symbol)
+		if err != nil && !apperrors.Is(err, apperrors.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	now := clock.Now(ctx)
+	if err := req.validate(inst, now); err != nil {
+		return nil, err
+	}
+
+	if err := checkTradable(inst, cfg); err != nil {
+		return nil, err
+	}
+	if err := checkNotional(req.Price, req.Quantity, cfg); err != nil {
+		return nil, err
+	}
+
+	o := &Order{
+		ID:            s.ids.Next(),
+		ClientOrderID: req.ClientOrderID,
+		UserID:        claims.Subject,
+		Symbol:        inst.Symbol,
+		Side:          req.Side,
+		Type:          req.Type,
+		TimeInForce:   req.TimeInForce,
+		Price:         req.Price,
+		Quantity:      req.Quantity,
+		Status:        StatusNew,
+		ExpiresAt:     req.ExpiresAt,
+		Version:       1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	_, err = s.tm.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		// Concurrent submits would otherwise all count the same open orders
+		if err := s.repo.LockUser(ctx, o.UserID); err != nil {
+			return nil, err
+		}
+		open, err := s.repo.CountOpen(ctx, o.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if open >= cfg.Trading.MaxOpenOrders {
+			return nil, apperrors.Newf(apperrors.ErrBusinessRule, CodeOpenOrderLimit,
+				"user has %d open orders", open).
+				WithMeta("max", cfg.Trading.MaxOpenOrders)
+		}
+		if err := s.repo.Insert(ctx, o); err != nil {
+			return nil, err
+		}
+		if err := s.recordEvent(ctx, o, EventCreated, "", nil); err != nil {
+			return nil, err
+		}
+		_, err = s.audit.Record(ctx, audit.Event{
+			Action:     audit.ActionOrderSubmit,
+			EntityType: "order",
+			EntityID:   o.ID.String(),
+			Metadata:   audit.Metadata(o),
+		})
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Get returns one of the authenticated user's orders
+func (s *Service) Get(ctx context.Context, orderID id.ID) (*Order, error) {
+	claims, err := user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.ReadTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return s.repo.Get(ctx, orderID, claims.Subject)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Order), nil
+}
+
+// List returns a page of the authenticated user's orders
+func (s *Service) List(ctx context.Context, q *pagination.Query) (*pagination.Page[*Order], error) {
+	claims, err := user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.ReadTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return s.repo.List(ctx, claims.Subject, q)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*pagination.Page[*Order]), nil
+}
+
+// Events returns the history of one of the authenticated user's orders
+func (s *Service) Events(ctx context.Context, orderID id.ID) ([]Event, error) {
+	claims, err := user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.db.ReadTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		// Reading the order first scopes the history to its owner
+		if _, err := s.repo.Get(ctx, orderID, claims.Subject); err != nil {
+			return nil, err
+		}
+		return s.repo.Events(ctx, orderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]Event), nil
+}
+
+// Amend changes the price or quantity of one of the authenticated user's
+// open limit orders
+func (s *Service) Amend(ctx context.Context, orderID id.ID, req *AmendOrderRequest) (*Order, error) {
+	claims, err := trader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.Price == nil && req.Quantity == nil {
+		return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "amend needs a price or quantity")
+	}
+	cfg, err := s.tenants.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.tm.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		o, err := s.repo.GetForUpdate(ctx, orderID, claims.Subject)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.requireOpen(); err != nil {
+			return nil, err
+		}
+		if o.Type != TypeLimit {
+			return nil, apperrors.Wrap(apperrors.ErrInvalidInput, "only limit orders can be amended")
+		}
+		inst, err := s.instruments.Get(ctx, o.Symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		ve := apperrors.NewValidationError()
+		changes := map[string]interface{}{}
+		if req.Price != nil {
+			if decimal.CheckPositive(ve, "price", *req.Price) {
+				inst.ValidatePrice(ve, "price", *req.Price)
+			}
+			changes["price"] = map[string]interface{}{"from": o.Price, "to": req.Price}
+		}
+		if req.Quantity != nil {
+			if decimal.CheckPositive(ve, "quantity", *req.Quantity) {
+				inst.ValidateQuantity(ve, "quantity", *req.Quantity)
+			}
+			if req.Quantity.Cmp(o.FilledQuantity) <= 0 {
+				ve.AddCode("quantity", apperrors.CodeFieldRange, map[string]interface{}{"min": o.FilledQuantity.String()})
+			}
+			changes["quantity"] = map[string]interface{}{"from": o.Quantity, "to": req.Quantity}
+		}
+		if err := ve.ErrOrNil(); err != nil {
+			return nil, err
+		}
+
+		if req.Price != nil {
+			o.Price = req.Price
+		}
+		if req.Quantity != nil {
+			o.Quantity = *req.Quantity
+		}
+		// The instrument or the tenant's limits may have changed since the
+		// order was placed
+		if err := checkTradable(inst, cfg); err != nil {
+			return nil, err
+		}
+		if err := checkNotional(o.Price, o.Quantity, cfg); err != nil {
+			return nil, err
+		}
+		o.UpdatedAt = clock.Now(ctx)
+		if err := s.repo.Update(ctx, o); err != nil {
+			return nil, err
+		}
+		if err := s.recordEvent(ctx, o, EventAmended, o.Status, changes); err != nil {
+			return nil, err
+		}
+		_, err = s.audit.Record(ctx, audit.Event{
+			Action:     audit.ActionOrderAmend,
+			EntityType: "order",
+			EntityID:   o.ID.String(),
+			Metadata:   audit.Metadata(changes),
+		})
+		return o, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Order), nil
+}
+
+// Cancel cancels one of the authenticated user's open orders
+func (s *Service) Cancel(ctx context.Context, orderID id.ID) (*Order, error) {
+	claims, err := trader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.change(ctx, orderID, claims.Subject, func(ctx context.Context, o *Order) (string, interface{}, error) {
+		if err := o.requireOpen(); err != nil {
+			return "", nil, err
+		}
+		if err := o.transition(StatusCancelled, clock.Now(ctx)); err != nil {
+			return "", nil, err
+		}
+		_, err := s.audit.Record(ctx, audit.Event{
+			Action:     audit.ActionOrderCancel,
+			EntityType: "order",
+			EntityID:   o.ID.String(),
+		})
+		return EventCancelled, nil, err
+	})
+}
+
+// Accept moves a new order onto the book. It is called by the matching
+// engine, which must put the order's tenant in ctx.
+func (s *Service) Accept(ctx context.Context, orderID id.ID) (*Order, error) {
+	return s.change(ctx, orderID, "", func(ctx context.Context, o *Order) (string, interface{}, error) {
+		return EventAccepted, nil, o.transition(StatusAccepted, clock.Now(ctx))
+	})
+}
+
+// Reject refuses a new order with a reason shown to the user
+func (s *Service) Reject(ctx context.Context, orderID id.ID, reason string) (*Order, error) {
+	return s.change(ctx, orderID, "", func(ctx context.Context, o *Order) (string, interface{}, error) {
+		if err := o.transition(StatusRejected, clock.Now(ctx)); err != nil {
+			return "", nil, err
+		}
+		o.RejectReason = reason
+		return EventRejected, map[string]string{"reason": reason}, nil
+	})
+}
+
+// Fill records an execution against an accepted order. Filling the
+// remaining quantity completes the order.
+func (s *Service) Fill(ctx context.Context, orderID id.ID, quantity, price decimal.Decimal) (*Order, error) {
+	return s.change(ctx, orderID, "", func(ctx context.Context, o *Order) (string, interface{}, error) {
+		if err := o.fill(quantity, price, clock.Now(ctx)); err != nil {
+			return "", nil, err
+		}
+		return EventFilled, map[string]decimal.Decimal{"quantity": quantity, "price": price}, nil
+	})
+}
+
+// Expire ends an open order whose time in force has run out
+func (s *Service) Expire(ctx context.Context, orderID id.ID) (*Order, error) {
+	return s.change(ctx, orderID, "", func(ctx context.Context, o *Order) (string, interface{}, error) {
+		return EventExpired, nil, o.transition(StatusExpired, clock.Now(ctx))
+	})
+}
+
+// change locks an order, applies fn and saves the order with the event
+// fn names. An empty userID matches any user's order.
+func (s *Service) change(ctx context.Context, orderID id.ID, userID string,
+	fn func(ctx context.Context, o *Order) (string, interface{}, error)) (*Order, error) {
+	result, err := s.tm.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		o, err := s.repo.GetForUpdate(ctx, orderID, userID)
+		if err != nil {
+			return nil, err
+		}
+		from := o.Status
+		eventType, data, err := fn(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.Update(ctx, o); err != nil {
+			return nil, err
+		}
+		return o, s.recordEvent(ctx, o, eventType, from, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Order), nil
+}
+
+// recordEvent appends an event for o's current status
+func (s *Service) recordEvent(ctx context.Context, o *Order, eventType string, from Status, data interface{}) error {
+	e := &Event{
+		ID:         s.ids.Next(),
+		OrderID:    o.ID,
+		Type:       eventType,
+		FromStatus: from,
+		ToStatus:   o.Status,
+		OccurredAt: o.UpdatedAt,
+	}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return apperrors.Wrapf(apperrors.ErrInternal, "order event data: %v", err)
+		}
+		e.Data = raw
+	}
+	return s.repo.InsertEvent(ctx, e)
+}
+
+// checkTradable fails if inst is not active or the tenant does not
+// allow it
+func checkTradable(inst *instruments.Instrument, cfg *config.Config) error {
+	if inst.Status != instruments.StatusActive || !cfg.Trading.AllowsInstrument(inst.Symbol) {
+		return apperrors.Newf(apperrors.ErrBusinessRule, CodeInstrumentNotTradable,
+			"instrument %s is %s", inst.Symbol, inst.Status).
+			WithMeta("symbol", inst.Symbol)
+	}
+	return nil
+}
+
+// checkNotional fails if price times quantity is above the tenant's
+// maximum order notional. Market orders have no price to value them by
+// until they fill.
+func checkNotional(price *decimal.Decimal, quantity decimal.Decimal, cfg *config.Config) error {
+	if price == nil {
+		return nil
+	}
+	limit, err := decimal.Parse(cfg.Trading.MaxOrderNotional)
+	if err != nil {
+		return apperrors.Wrapf(apperrors.ErrInternal, "max order notional %q: %v", cfg.Trading.MaxOrderNotional, err)
+	}
+	if value := price.Mul(quantity); value.Cmp(limit) > 0 {
+		return apperrors.Newf(apperrors.ErrBusinessRule, CodeNotionalExceeded,
+			"order value %s exceeds %s", value, limit).
+			WithMeta("max", limit.String())
+	}
+	return nil
+}
+
+// validate checks the request against the order rules and, when known,
+// the instrument. It fills in the default time in force.
+func (req *CreateOrderRequest) validate(inst *instruments.Instrument, now time.Time) error {
+	ve := apperrors.NewValidationError()
+
+	if len(req.ClientOrderID) > maxClientOrderIDLength {
+		ve.AddCode("client_order_id", apperrors.CodeFieldTooLong, map[string]interface{}{"max": maxClientOrderIDLength})
+	}
+	switch {
+	case req.Symbol == "":
+		ve.AddCode("symbol", apperrors.CodeFieldRequired, nil)
+	case inst == nil:
+		ve.AddCode("symbol", apperrors.CodeFieldInvalid, nil)
+	}
+	switch req.Side {
+	case SideBuy, SideSell:
+	default:
+		ve.AddCode("side", apperrors.CodeFieldInvalid, nil)
+	}
+
+	switch req.Type {
+	case TypeLimit:
+		if req.TimeInForce == "" {
+			req.TimeInForce = TimeInForceGTC
+		}
+		if req.Price == nil {
+			ve.AddCode("price", apperrors.CodeFieldRequired, nil)
+		} else if decimal.CheckPositive(ve, "price", *req.Price) && inst != nil {
+			inst.ValidatePrice(ve, "price", *req.Price)
+		}
+	case TypeMarket:
+		if req.TimeInForce == "" {
+			req.TimeInForce = TimeInForceIOC
+		}
+		// A market order never rests on the book
+		if req.TimeInForce != TimeInForceIOC && req.TimeInForce != TimeInForceFOK {
+			ve.AddCode("time_in_force", apperrors.CodeFieldInvalid, nil)
+		}
+		if req.Price != nil {
+			ve.AddCode("price", apperrors.CodeFieldInvalid, nil)
+		}
+	default:
+		ve.AddCode("type", apperrors.CodeFieldInvalid, nil)
+	}
+
+	switch req.TimeInForce {
+	case TimeInForceGTD:
+		if req.ExpiresAt == nil {
+			ve.AddCode("expires_at", apperrors.CodeFieldRequired, nil)
+		} else if !req.ExpiresAt.After(now) {
+			ve.AddCode("expires_at", apperrors.CodeFieldInvalid, nil)
+		}
+	case TimeInForceGTC, TimeInForceDay, TimeInForceIOC, TimeInForceFOK, "":
+		if req.ExpiresAt != nil {
+			ve.AddCode("expires_at", apperrors.CodeFieldInvalid, nil)
+		}
+	default:
+		ve.AddCode("time_in_force", apperrors.CodeFieldInvalid, nil)
+	}
+
+	if decimal.CheckPositive(ve, "quantity", req.Quantity) && inst != nil {
+		inst.ValidateQuantity(ve, "quantity", req.Quantity)
+	}
+
+	return ve.ErrOrNil()
+}
+
+// user returns the claims of the authenticated user
+func user(ctx context.Context) (*auth.Claims, error) {
+	claims, ok := auth.ClaimsFrom(ctx)
+	if !ok {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "authentication required")
+	}
+	return claims, nil
+}
+
+// trader returns the claims of the authenticated user if they may place
+// and change orders
+func trader(ctx context.Context) (*auth.Claims, error) {
+	claims, err := user(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Role != users.RoleTrader && claims.Role != users.RoleAdmin {
+		return nil, apperrors.Wrapf(apperrors.ErrForbidden, "role %s cannot trade", claims.Role)
+	}
+	return claims, nil
+}
@@ -0,0 +1,189 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/request"
+)
+
+// HealthCheck reports the health of one dependency.
+// The returned details are included in the health response.
+type HealthCheck func(ctx context.Context) (interface{}, error)
+
+// Handler serves the operational endpoints of the admin listener
+type Handler struct {
+	config    *config.Config
+	logger    logger.Logger
+	startedAt time.Time
+
+	mu     sync.RWMutex
+	checks []namedCheck
+	mounts []namedMount
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+type namedMount struct {
+	pattern string
+	handler http.Handler
+}
+
+// NewHandler creates a new admin handler
+func NewHandler(cfg *config.Config, log logger.Logger) *Handler {
+	return &Handler{
+		config:    cfg,
+		logger:    log,
+		startedAt: time.Now(),
+	}
+}
+
+// AddHealthCheck registers a dependency check shown by GET /health
+func (h *Handler) AddHealthCheck(name string, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+// Mount adds operational routes owned by another package, e.g. the
+// scheduler's run history. Call it before Routes.
+func (h *Handler) Mount(pattern string, handler http.Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.mounts = append(h.mounts, namedMount{pattern: pattern, handler: handler})
+}
+
+// Routes returns the admin router
+func (h *Handler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequestID)
+	r.Use(middleware.Recoverer)
+
+	r.Mount("/debug", middleware.Profiler())
+	r.Method(http.MethodGet, "/metrics", metrics.Handler())
+	r.Get("/health", h.health)
+	r.Get("/config", h.getConfig)
+	r.Get("/loglevel", h.getLogLevel)
+	r.Put("/loglevel", h.setLogLevel)
+
+	h.mu.RLock()
+	for _, m := range h.mounts {
+		r.Mount(m.pattern, m.handler)
+	}
+	h.mu.RUnlock()
+
+	return r
+}
+
+// checkResult is the outcome of one health check
+type checkResult struct {
+	Status   string      `json:"status"`
+	Error    string      `json:"error,omitempty"`
+	Duration string      `json:"duration"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// health runs every registered check and reports details
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	h.mu.RLock()
+	checks := append([]namedCheck(nil), h.checks...)
+	h.mu.RUnlock()
+
+	status := "ok"
+	results := make(map[string]checkResult, len(checks))
+	for _, c := range checks {
+		start := time.Now()
+		details, err := c.check(ctx)
+		result := checkResult{
+			Status:   "ok",
+			Duration: time.Since(start).String(),
+			Details:  details,
+		}
+		if err != nil {
+			result.Status = "failing"
+			result.Error = err.Error()
+			status = "failing"
+		}
+		results[c.name] = result
+	}
+
+	code := http.StatusOK
+	if status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"status":      status,
+		"environment": h.config.App.Environment,
+		"uptime":      time.Since(h.startedAt).Round(time.Second).String(),
+		"go_version":  runtime.Version(),
+		"goroutines":  runtime.NumGoroutine(),
+		"checks":      results,
+	})
+}
+
+// getConfig returns the effective configuration with secrets masked
+func (h *Handler) getConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.config.Redacted())
+}
+
+// logLevelRequest is the body of GET and PUT /loglevel
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+func (h *Handler) getLogLevel(w http.ResponseWriter, r *http.Request) {
+	ctrl, ok := h.logger.(logger.LevelController)
+	if !ok {
+		apperrors.WriteError(w, r, apperrors.Wrap(apperrors.ErrInternal, "logger does not support level changes"), h.logger)
+		return
+	}
+	writeJSON(w, http.StatusOK, logLevelRequest{Level: ctrl.Level()})
+}
+
+func (h *Handler) setLogLevel(w http.ResponseWriter, r *http.Request) {
+	ctrl, ok := h.logger.(logger.LevelController)
+	if !ok {
+		apperrors.WriteError(w, r, apperrors.Wrap(apperrors.ErrInternal, "logger does not support level changes"), h.logger)
+		return
+	}
+
+	var req logLevelRequest
+	if err := request.DecodeJSON(r, &req); err != nil {
+		apperrors.WriteError(w, r, err, h.logger)
+		return
+	}
+	if err := ctrl.SetLevel(req.Level); err != nil {
+		ve := apperrors.NewValidationError()
+		ve.Add("level", err.Error())
+		apperrors.WriteError(w, r, ve, h.logger)
+		return
+	}
+
+	h.logger.Info("log level changed", logger.String("level", req.Level))
+	writeJSON(w, http.StatusOK, logLevelRequest{Level: ctrl.Level()})
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,320 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/clock"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// Actions recorded by the platform. Values are stable; they are stored
+// and exported to compliance.
+const (
+	ActionLogin            = "auth.login"
+	ActionLoginFailed      = "auth.login_failed"
+	ActionLogout           = "auth.logout"
+	ActionUserCreate       = "user.create"
+	ActionPermissionChange = "user.permission_change"
+	ActionOrderSubmit      = "order.submit"
+	ActionOrderAmend       = "order.amend"
+	ActionOrderCancel      = "order.cancel"
+	ActionRiskOverride     = "risk.override"
+	ActionWithdrawal       = "account.withdrawal"
+)
+
+// Actor types
+const (
+	ActorUser   = "user"
+	ActorSystem = "system"
+	ActorCLI    = "cli"
+)
+
+// chainLockKey, with the chain's hash, serializes appends to a chain so
+// every event links to the one before it
+const chainLockKey int32 = 7_414_042
+
+// Actor is who performed an action
+type Actor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Event is one audit record. Hashes are hex encoded in JSON.
+type Event struct {
+	ID         int64           `json:"id"`
+	TenantID   string          `json:"tenant_id,omitempty"` // the chain the event belongs to
+	OccurredAt time.Time       `json:"occurred_at"`
+	Actor      Actor           `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type,omitempty"`
+	EntityID   string          `json:"entity_id,omitempty"`
+	RequestID  string          `json:"request_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	PrevHash   HexBytes        `json:"prev_hash,omitempty"`
+	Hash       HexBytes        `json:"hash"`
+}
+
+// HexBytes marshals as a hex string
+type HexBytes []byte
+
+// MarshalJSON implements json.Marshaler
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(fmt.Sprintf("%x", []byte(b)))), nil
+}
+
+// Context keys for the actor and request details
+type contextKey string
+
+const (
+	actorKey   contextKey = "audit_actor"
+	requestKey contextKey = "audit_request"
+)
+
+// WithActor returns a context whose events are attributed to actor.
+// Authentication middleware sets it once the caller is known.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFrom returns the actor set with WithActor
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey).(Actor)
+	return actor, ok
+}
+
+type requestInfo struct {
+	ip string
+}
+
+// Middleware stores the client IP for events recorded while handling
+// the request. Use it after middleware.RealIP.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+		ctx := context.WithValue(r.Context(), requestKey, requestInfo{ip: ip})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Log appends and queries audit events
+type Log struct {
+	pool *pgxpool.Pool
+	tm   *database.PostgresTransactionManager
+}
+
+// NewLog creates an audit log
+func NewLog(pool *pgxpool.Pool) *Log {
+	return &Log{
+		pool: pool,
+		tm:   database.NewPostgresTransactionManager(pool),
+	}
+}
+
+// Record appends an event. Call it inside the action's WithTransaction so
+// the event commits or rolls back with the action; outside a transaction
+// it is written in one of its own. The actor, request ID and IP are taken
+// from ctx when not set on the event.
+func (l *Log) Record(ctx context.Context, e Event) (*Event, error) {
+	if e.Action == "" {
+		return nil, apperrors.New(apperrors.ErrInternal, apperrors.CodeInternal, "").
+			WithInternal("audit event needs an action")
+	}
+	if e.Actor.Type == "" {
+		actor, ok := ActorFrom(ctx)
+		if !ok {
+			return nil, apperrors.New(apperrors.ErrInternal, apperrors.CodeInternal, "").
+				WithInternal("audit event %s has no actor", e.Action)
+		}
+		e.Actor = actor
+	}
+	if e.RequestID == "" {
+		e.RequestID = middleware.GetReqID(ctx)
+	}
+	if info, ok := ctx.Value(requestKey).(requestInfo); ok && e.IP == "" {
+		e.IP = info.ip
+	}
+
+	metadata, err := canonicalJSON(e.Metadata)
+	if err != nil {
+		return nil, apperrors.New(apperrors.ErrInternal, apperrors.CodeInternal, "").
+			WithInternal("audit metadata").
+			WithCause(err)
+	}
+	e.Metadata = metadata
+
+	if database.GetTx(ctx) != nil {
+		return l.append(ctx, e)
+	}
+	result, err := l.tm.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return l.append(ctx, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*Event), nil
+}
+
+// append links the event to its tenant's chain and inserts it. The
+// transaction lock is held until commit, so a concurrent append sees
+// this row as its predecessor. Appends of one tenant therefore wait for
+// each other's business transactions; keep those short.
+func (l *Log) append(ctx context.Context, e Event) (*Event, error) {
+	e.TenantID, _ = database.TenantID(ctx)
+
+	tx := database.GetTx(ctx)
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1, hashtext($2))", chainLockKey, e.TenantID); err != nil {
+		return nil, fmt.Errorf("%w: failed to lock audit chain: %v", apperrors.ErrDatabase, err)
+	}
+
+	var prev []byte
+	err := tx.QueryRow(ctx,
+		"SELECT hash FROM audit_events WHERE tenant_id = $1 ORDER BY id DESC LIMIT 1",
+		e.TenantID,
+	).Scan(&prev)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%w: failed to read audit chain: %v", apperrors.ErrDatabase, err)
+	}
+
+	// Postgres stores microseconds; hash what will be read back
+	e.OccurredAt = clock.Now(ctx).UTC().Truncate(time.Microsecond)
+	e.PrevHash = prev
+	e.Hash = e.computeHash()
+
+	err = tx.QueryRow(ctx,
+		`INSERT INTO audit_events
+		   (tenant_id, occurred_at, actor_type, actor_id, action, entity_type, entity_id,
+		    request_id, ip, metadata, prev_hash, hash)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		 RETURNING id`,
+		e.TenantID, e.OccurredAt, e.Actor.Type, e.Actor.ID, e.Action, e.EntityType, e.EntityID,
+		e.RequestID, e.IP, string(e.Metadata), []byte(e.PrevHash), []byte(e.Hash),
+	).Scan(&e.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to record audit event: %v", apperrors.ErrDatabase, err)
+	}
+	return &e, nil
+}
+
+// computeHash returns SHA-256 over the previous hash and the event
+// fields, each length-prefixed so field boundaries are unambiguous. The
+// tenant is only hashed when set, so events from before per-tenant
+// chains keep their hashes.
+func (e *Event) computeHash() []byte {
+	h := sha256.New()
+	var buf [binary.MaxVarintLen64]byte
+	write := func(b []byte) {
+		n := binary.PutUvarint(buf[:], uint64(len(b)))
+		h.Write(buf[:n])
+		h.Write(b)
+	}
+
+	write(e.PrevHash)
+	write([]byte(e.OccurredAt.UTC().Format(time.RFC3339Nano)))
+	write([]byte(e.Actor.Type))
+	write([]byte(e.Actor.ID))
+	write([]byte(e.Action))
+	write([]byte(e.EntityType))
+	write([]byte(e.EntityID))
+	write([]byte(e.RequestID))
+	write([]byte(e.IP))
+	write(e.Metadata)
+	if e.TenantID != "" {
+		write([]byte(e.TenantID))
+	}
+	return h.Sum(nil)
+}
+
+// canonicalJSON re-encodes metadata with sorted keys and no whitespace,
+// so the hash survives JSONB reformatting it on the way back out
+func canonicalJSON(raw json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return json.RawMessage("{}"), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, ok := v.(map[string]interface{}); !ok {
+		return nil, errors.New("must be a JSON object")
+	}
+	return json.Marshal(v)
+}
+
+// Metadata encodes v for Event.Metadata, e.g.
+// audit.Metadata(map[string]any{"role": "admin"})
+func Metadata(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage(fmt.Sprintf(`{"error":%q}`, err.Error()))
+	}
+	return data
+}
+
+// VerifyResult reports the outcome of a chain check
+type VerifyResult struct {
+	Checked  int64  `json:"checked"`
+	Valid    bool   `json:"valid"`
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Verify recomputes every tenant's hash chain from its first event and
+// reports the first event that doesn't match
+func (l *Log) Verify(ctx context.Context) (*VerifyResult, error) {
+	rows, err := l.pool.Query(ctx, "SELECT "+eventColumns+" FROM audit_events ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read audit events: %v", apperrors.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	result := &VerifyResult{Valid: true}
+	prevs := make(map[string][]byte)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Checked++
+
+		reason := ""
+		if !bytes.Equal(e.PrevHash, prevs[e.TenantID]) {
+			reason = "previous hash does not match the preceding event"
+		} else if metadata, err := canonicalJSON(e.Metadata); err != nil {
+			reason = "metadata is not valid JSON"
+		} else if e.Metadata = metadata; !bytes.Equal(e.computeHash(), e.Hash) {
+			reason = "hash does not match event contents"
+		}
+		if reason != "" {
+			result.Valid = false
+			result.BrokenAt = e.ID
+			result.Reason = reason
+			return result, nil
+		}
+		prevs[e.TenantID] = e.Hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: failed to read audit events: %v", apperrors.ErrDatabase, err)
+	}
+	return result, nil
+}
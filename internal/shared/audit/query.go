@@ -0,0 +1,287 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+const eventColumns = `id, tenant_id, occurred_at, actor_type, actor_id, action, entity_type, entity_id,
+	request_id, ip, metadata, prev_hash, hash`
+
+// Filter selects audit events. Zero fields match everything; From is
+// inclusive and To exclusive.
+type Filter struct {
+	ActorType  string
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       time.Time
+	To         time.Time
+
+	// BeforeID pages backwards from an earlier result's last ID
+	BeforeID int64
+
+	// Limit caps the result; 0 means no limit
+	Limit int
+}
+
+// ParseFilter reads a filter from query parameters: actor_type, actor_id,
+// action, entity_type, entity_id, from and to (RFC 3339), before_id and
+// limit
+func ParseFilter(r *http.Request, limit int) (Filter, error) {
+	q := r.URL.Query()
+	f := Filter{
+		ActorType:  q.Get("actor_type"),
+		ActorID:    q.Get("actor_id"),
+		Action:     q.Get("action"),
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+		Limit:      limit,
+	}
+
+	ve := apperrors.NewValidationError()
+	for name, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				ve.Add(name, "must be an RFC 3339 timestamp")
+				continue
+			}
+			*dst = t
+		}
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.To.After(f.From) {
+		ve.Add("to", "must be after from")
+	}
+	if v := q.Get("before_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 1 {
+			ve.AddCode("before_id", apperrors.CodeFieldInvalid, nil)
+		}
+		f.BeforeID = id
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			ve.AddCode("limit", apperrors.CodeFieldRange, map[string]interface{}{"min": 1, "max": maxLimit})
+		}
+		f.Limit = n
+	}
+	return f, ve.ErrOrNil()
+}
+
+// where builds the WHERE clause and arguments for a filter
+func (f Filter) where() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.ActorType != "" {
+		add("actor_type = $%d", f.ActorType)
+	}
+	if f.ActorID != "" {
+		add("actor_id = $%d", f.ActorID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.EntityType != "" {
+		add("entity_type = $%d", f.EntityType)
+	}
+	if f.EntityID != "" {
+		add("entity_id = $%d", f.EntityID)
+	}
+	if !f.From.IsZero() {
+		add("occurred_at >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("occurred_at < $%d", f.To)
+	}
+	if f.BeforeID > 0 {
+		add("id < $%d", f.BeforeID)
+	}
+
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// query runs a filtered select, newest first
+func (l *Log) query(ctx context.Context, f Filter) (pgx.Rows, error) {
+	where, args := f.where()
+	sql := "SELECT " + eventColumns + " FROM audit_events" + where + " ORDER BY id DESC"
+	if f.Limit > 0 {
+		sql += fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	rows, err := l.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to query audit events: %v", apperrors.ErrDatabase, err)
+	}
+	return rows, nil
+}
+
+// Query returns matching events, newest first
+func (l *Log) Query(ctx context.Context, f Filter) ([]Event, error) {
+	rows, err := l.query(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: failed to read audit events: %v", apperrors.ErrDatabase, err)
+	}
+	return events, nil
+}
+
+// ExportCSV writes matching events as CSV with a header row, streaming
+// rows as they are read
+func (l *Log) ExportCSV(ctx context.Context, w io.Writer, f Filter) error {
+	rows, err := l.query(ctx, f)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"id", "tenant_id", "occurred_at", "actor_type", "actor_id", "action", "entity_type", "entity_id",
+		"request_id", "ip", "metadata", "prev_hash", "hash",
+	})
+	for n := 1; rows.Next(); n++ {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		cw.Write([]string{
+			strconv.FormatInt(e.ID, 10),
+			e.TenantID,
+			e.OccurredAt.UTC().Format(time.RFC3339Nano),
+			e.Actor.Type, e.Actor.ID, e.Action, e.EntityType, e.EntityID,
+			e.RequestID, e.IP, string(e.Metadata),
+			fmt.Sprintf("%x", []byte(e.PrevHash)), fmt.Sprintf("%x", []byte(e.Hash)),
+		})
+		if n%500 == 0 {
+			cw.Flush()
+		}
+	}
+	cw.Flush()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: failed to read audit events: %v", apperrors.ErrDatabase, err)
+	}
+	return cw.Error()
+}
+
+func scanEvent(rows pgx.Rows) (*Event, error) {
+	var e Event
+	var metadata []byte
+	var prev, hash []byte
+	err := rows.Scan(&e.ID, &e.TenantID, &e.OccurredAt, &e.Actor.Type, &e.Actor.ID, &e.Action,
+		&e.EntityType, &e.EntityID, &e.RequestID, &e.IP, &metadata, &prev, &hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to scan audit event: %v", apperrors.ErrDatabase, err)
+	}
+	e.Metadata = metadata
+	e.PrevHash = prev
+	e.Hash = hash
+	return &e, nil
+}
+
+// AdminRoutes serves the audit log for the admin listener:
+//
+//	GET /events          matching events as JSON, newest first
+//	GET /events/export   matching events as CSV, no default limit
+//	GET /verify          hash chain check
+func (l *Log) AdminRoutes(log logger.Logger) http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		f, err := ParseFilter(r, defaultLimit)
+		if err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+		events, err := l.Query(r.Context(), f)
+		if err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+
+		resp := struct {
+			Events   []Event `json:"events"`
+			BeforeID int64   `json:"next_before_id,omitempty"`
+		}{Events: events}
+		if len(events) == f.Limit {
+			resp.BeforeID = events[len(events)-1].ID
+		}
+		writeJSON(w, resp)
+	})
+
+	r.Get("/events/export", func(w http.ResponseWriter, r *http.Request) {
+		f, err := ParseFilter(r, 0)
+		if err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="audit-events-%s.csv"`, time.Now().UTC().Format("20060102T150405Z")))
+		if err := l.ExportCSV(r.Context(), w, f); err != nil {
+			// Headers are sent; a truncated file is all the client can get
+			log.Error("audit export failed", logger.Error(err))
+		}
+	})
+
+	r.Get("/verify", func(w http.ResponseWriter, r *http.Request) {
+		result, err := l.Verify(r.Context())
+		if err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+		if !result.Valid {
+			log.Error("audit chain broken",
+				logger.Int64("event_id", result.BrokenAt),
+				logger.String("reason", result.Reason),
+			)
+		}
+		writeJSON(w, result)
+	})
+
+	return r
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/audit"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/clock"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+)
+
+// leeway absorbs clock skew between the issuer and this instance
+const leeway = 30 * time.Second
+
+// Claims are the JWT claims the platform issues and accepts
+type Claims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	TenantID  string `json:"tid"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Tokens issues and verifies HS256 JWTs
+type Tokens struct {
+	secret []byte
+	expiry time.Duration
+	clock  clock.Clock
+}
+
+// NewTokens creates a token issuer and verifier from the JWT config
+func NewTokens(cfg *config.JWTConfig) *Tokens {
+	return &Tokens{secret: []byte(cfg.Secret), expiry: cfg.Expiry, clock: clock.Real{}}
+}
+
+// WithClock issues and expires tokens by c instead of the wall clock
+func (t *Tokens) WithClock(c clock.Clock) *Tokens {
+	t.clock = c
+	return t
+}
+
+// Issue signs a token for a user of a tenant
+func (t *Tokens) Issue(subject, role, tenantID string) (string, error) {
+	if len(t.secret) == 0 {
+		return "", errors.New("JWT_SECRET is not set")
+	}
+	now := t.clock.Now()
+	payload, err := json.Marshal(Claims{
+		Subject:   subject,
+		Role:      role,
+		TenantID:  tenantID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(t.expiry).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + t.sign(signed), nil
+}
+
+// Verify checks a token's signature and expiry and returns its claims.
+// Failures match ErrUnauthorized.
+func (t *Tokens) Verify(token string) (*Claims, error) {
+	if len(t.secret) == 0 {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "authentication is not configured")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "malformed token")
+	}
+
+	// Only HS256 is accepted; checking the header stops alg=none tokens
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "malformed token")
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if json.Unmarshal(header, &h) != nil || h.Alg != "HS256" {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "unsupported token algorithm")
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, t.mac(parts[0]+"."+parts[1])) {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "invalid token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "malformed token")
+	}
+	claims := &Claims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "malformed token claims")
+	}
+	if claims.ExpiresAt == 0 || t.clock.Now().Add(-leeway).Unix() > claims.ExpiresAt {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "token expired")
+	}
+	if claims.Subject == "" || !tenant.ValidID(claims.TenantID) {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "token has no user or tenant")
+	}
+	return claims, nil
+}
+
+func (t *Tokens) mac(signed string) []byte {
+	m := hmac.New(sha256.New, t.secret)
+	m.Write([]byte(signed))
+	return m.Sum(nil)
+}
+
+func (t *Tokens) sign(signed string) string {
+	return base64.RawURLEncoding.EncodeToString(t.mac(signed))
+}
+
+// Context key for claims
+type contextKey string
+
+const claimsKey contextKey = "claims"
+
+// ClaimsFrom returns the claims of the authenticated request
+func ClaimsFrom(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*Claims)
+	return claims, ok
+}
+
+// Middleware authenticates bearer tokens. A valid token puts its claims,
+// tenant and audit actor in the request context; an invalid one is
+// rejected. Requests without a token pass through anonymously, so
+// routes that need a user mount tenant.Require.
+func (t *Tokens) Middleware(log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				apperrors.WriteError(w, r, apperrors.Wrap(apperrors.ErrUnauthorized, "expected a bearer token"), log)
+				return
+			}
+
+			claims, err := t.Verify(strings.TrimSpace(token))
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				apperrors.WriteError(w, r, err, log)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), claimsKey, claims)
+			ctx = tenant.WithID(ctx, claims.TenantID)
+			ctx = audit.WithActor(ctx, audit.Actor{Type: audit.ActorUser, ID: claims.Subject})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+)
+
+var (
+	requests = metrics.NewCounterVec(
+		"cache_requests_total",
+		"Cache lookups by cache and result (hit, negative_hit, miss).",
+		"cache", "result",
+	)
+	evictions = metrics.NewCounterVec(
+		"cache_evictions_total",
+		"Entries removed by cache and reason (capacity, expired, invalidated).",
+		"cache", "reason",
+	)
+	entries = metrics.NewGaugeVec(
+		"cache_entries",
+		"Entries currently held by cache.",
+		"cache",
+	)
+	loadDuration = metrics.NewHistogramVec(
+		"cache_load_duration_seconds",
+		"Time spent loading missed entries by cache.",
+		nil,
+		"cache",
+	)
+	loadErrors = metrics.NewCounterVec(
+		"cache_load_errors_total",
+		"Failed loads by cache; not-found results are cached, not counted.",
+		"cache",
+	)
+)
+
+// Options configure a cache
+type Options struct {
+	// Size is the maximum number of entries; the least recently used
+	// entry is evicted beyond it
+	Size int
+
+	// TTL is how long a loaded value is served
+	TTL time.Duration
+
+	// NegativeTTL is how long a not-found result is served; zero
+	// disables negative caching
+	NegativeTTL time.Duration
+}
+
+// Loader loads the value for a missed key. Returning an error matching
+// errors.ErrNotFound caches the miss for NegativeTTL.
+type Loader[V any] func(ctx context.Context) (V, error)
+
+type entry[V any] struct {
+	key     string
+	value   V
+	err     error // set for negative entries
+	expires time.Time
+}
+
+// call is an in-flight load shared by concurrent callers
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// Cache is a size-bounded LRU cache with TTLs, negative caching and
+// single-flight loading. Keys are compared by their fmt.Sprint form,
+// which is also how they are named in invalidation messages.
+type Cache[K comparable, V any] struct {
+	name string
+	opts Options
+
+	mu    sync.Mutex
+	lru   *list.List // front is most recently used
+	items map[string]*list.Element
+	calls map[string]*call[V]
+
+	// gen increments on every invalidation; a load that started before
+	// one is not stored, so it can't resurrect stale data
+	gen uint64
+}
+
+// New creates a cache. name labels its metrics and addresses it in
+// invalidation messages, so it must be unique per process.
+func New[K comparable, V any](name string, opts Options) *Cache[K, V] {
+	if opts.Size < 1 {
+		opts.Size = 1000
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	return &Cache[K, V]{
+		name:  name,
+		opts:  opts,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+		calls: make(map[string]*call[V]),
+	}
+}
+
+// Name returns the cache name
+func (c *Cache[K, V]) Name() string {
+	return c.name
+}
+
+func keyString[K comparable](key K) string {
+	if s, ok := any(key).(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// Get returns the cached value for key, calling load on a miss. Concurrent
+// misses for the same key share one load. The load is not cancelled when
+// ctx is, since other callers may be waiting for it, and it runs outside
+// any transaction in ctx, which may end before it does.
+func (c *Cache[K, V]) Get(ctx context.Context, key K, load Loader[V]) (V, error) {
+	k := keyString(key)
+
+	c.mu.Lock()
+	if v, err, ok := c.lookup(k); ok {
+		c.mu.Unlock()
+		return v, err
+	}
+	requests.With(c.name, "miss").Inc()
+
+	cl, inFlight := c.calls[k]
+	if !inFlight {
+		cl = &call[V]{done: make(chan struct{})}
+		c.calls[k] = cl
+		go c.load(database.WithoutTx(context.WithoutCancel(ctx)), k, cl, load, c.gen)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// lookup returns a live entry; c.mu must be held
+func (c *Cache[K, V]) lookup(k string) (V, error, bool) {
+	var zero V
+	el, ok := c.items[k]
+	if !ok {
+		return zero, nil, false
+	}
+	e := el.Value.(*entry[V])
+	if time.Now().After(e.expires) {
+		c.remove(el, "expired")
+		return zero, nil, false
+	}
+	c.lru.MoveToFront(el)
+	if e.err != nil {
+		requests.With(c.name, "negative_hit").Inc()
+		return zero, e.err, true
+	}
+	requests.With(c.name, "hit").Inc()
+	return e.value, nil, true
+}
+
+// load runs a shared load and stores its result
+func (c *Cache[K, V]) load(ctx context.Context, k string, cl *call[V], load Loader[V], gen uint64) {
+	start := time.Now()
+	defer func() {
+		if p := recover(); p != nil {
+			cl.err = fmt.Errorf("%w: cache %s load panicked: %v", apperrors.ErrInternal, c.name, p)
+			c.mu.Lock()
+			delete(c.calls, k)
+			c.mu.Unlock()
+			close(cl.done)
+		}
+	}()
+
+	cl.value, cl.err = load(ctx)
+	loadDuration.With(c.name).ObserveDuration(time.Since(start))
+
+	c.mu.Lock()
+	delete(c.calls, k)
+	if c.gen == gen {
+		switch {
+		case cl.err == nil:
+			c.store(&entry[V]{key: k, value: cl.value, expires: time.Now().Add(c.opts.TTL)})
+		case apperrors.Is(cl.err, apperrors.ErrNotFound) && c.opts.NegativeTTL > 0:
+			c.store(&entry[V]{key: k, err: cl.err, expires: time.Now().Add(c.opts.NegativeTTL)})
+		}
+	}
+	c.mu.Unlock()
+
+	if cl.err != nil && !apperrors.Is(cl.err, apperrors.ErrNotFound) {
+		loadErrors.With(c.name).Inc()
+	}
+	close(cl.done)
+}
+
+// Set stores a value, e.g. one just written by this instance
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(&entry[V]{key: keyString(key), value: value, expires: time.Now().Add(c.opts.TTL)})
+}
+
+// store inserts or replaces an entry and enforces the size; c.mu must be
+// held
+func (c *Cache[K, V]) store(e *entry[V]) {
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.items[e.key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.opts.Size {
+		c.remove(c.lru.Back(), "capacity")
+	}
+	entries.With(c.name).Set(float64(c.lru.Len()))
+}
+
+// remove drops an element; c.mu must be held
+func (c *Cache[K, V]) remove(el *list.Element, reason string) {
+	c.lru.Remove(el)
+	delete(c.items, el.Value.(*entry[V]).key)
+	evictions.With(c.name, reason).Inc()
+	entries.With(c.name).Set(float64(c.lru.Len()))
+}
+
+// Delete removes keys from this instance only. Use Invalidate to reach
+// every instance.
+func (c *Cache[K, V]) Delete(keys ...K) {
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = keyString(key)
+	}
+	c.InvalidateKeys(names)
+}
+
+// InvalidateKeys removes keys given in their string form
+func (c *Cache[K, V]) InvalidateKeys(keys []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	for _, k := range keys {
+		if el, ok := c.items[k]; ok {
+			c.remove(el, "invalidated")
+		}
+	}
+}
+
+// Purge removes every entry
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	if n := c.lru.Len(); n > 0 {
+		evictions.With(c.name, "invalidated").Add(float64(n))
+	}
+	c.lru.Init()
+	c.items = make(map[string]*list.Element)
+	entries.With(c.name).Set(0)
+}
+
+// Len returns the number of entries, including expired ones not yet
+// removed
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
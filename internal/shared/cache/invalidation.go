@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// notifyChannel carries invalidations between instances
+const notifyChannel = "cache_invalidation"
+
+// message names the keys to drop from a cache; no keys drops everything
+type message struct {
+	Cache string   `json:"cache"`
+	Keys  []string `json:"keys,omitempty"`
+}
+
+// Invalidatable is a cache that can be invalidated by name
+type Invalidatable interface {
+	Name() string
+	InvalidateKeys(keys []string)
+	Purge()
+}
+
+// Invalidate tells every instance, this one included, to drop keys from
+// the named cache; with no keys the whole cache is dropped. Inside
+// WithTransaction the message is sent on commit, after the change is
+// visible, and not at all on rollback. It needs only a pool, so tools
+// that write the underlying rows can invalidate running servers.
+func Invalidate(ctx context.Context, pool *pgxpool.Pool, cache string, keys ...string) error {
+	// Keys are batched to stay under the NOTIFY payload limit
+	const batch = 200
+	for {
+		n := min(len(keys), batch)
+		data, err := json.Marshal(message{Cache: cache, Keys: keys[:n]})
+		if err != nil {
+			return fmt.Errorf("failed to encode cache invalidation: %w", err)
+		}
+		if _, err := database.GetQuerier(ctx, pool).Exec(ctx,
+			"SELECT pg_notify($1, $2)", notifyChannel, string(data),
+		); err != nil {
+			return fmt.Errorf("%w: failed to publish cache invalidation: %v", apperrors.ErrDatabase, err)
+		}
+		keys = keys[n:]
+		if len(keys) == 0 {
+			return nil
+		}
+	}
+}
+
+// Invalidator applies invalidation messages to the caches attached to it
+type Invalidator struct {
+	bus    *database.Bus
+	logger logger.Logger
+
+	mu     sync.RWMutex
+	caches map[string][]Invalidatable
+}
+
+// NewInvalidator creates an invalidator listening on bus
+func NewInvalidator(bus *database.Bus, log logger.Logger) *Invalidator {
+	return &Invalidator{
+		bus:    bus,
+		logger: log,
+		caches: make(map[string][]Invalidatable),
+	}
+}
+
+// Attach subscribes caches to invalidation messages by their names
+func (inv *Invalidator) Attach(caches ...Invalidatable) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	for _, c := range caches {
+		inv.caches[c.Name()] = append(inv.caches[c.Name()], c)
+	}
+}
+
+// Run applies messages until ctx is cancelled. After the listener
+// reconnects every cache is purged, since messages may have been lost.
+func (inv *Invalidator) Run(ctx context.Context) error {
+	sub := inv.bus.Subscribe(notifyChannel, 256)
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if n.Resync {
+				inv.purgeAll()
+				continue
+			}
+			msg, err := database.Decode[message](n)
+			if err != nil {
+				inv.logger.Warn("invalid cache invalidation", logger.Error(err))
+				continue
+			}
+			inv.apply(msg)
+		}
+	}
+}
+
+func (inv *Invalidator) apply(msg message) {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	for _, c := range inv.caches[msg.Cache] {
+		if len(msg.Keys) == 0 {
+			c.Purge()
+		} else {
+			c.InvalidateKeys(msg.Keys)
+		}
+	}
+}
+
+func (inv *Invalidator) purgeAll() {
+	inv.mu.RLock()
+	defer inv.mu.RUnlock()
+	for _, list := range inv.caches {
+		for _, c := range list {
+			c.Purge()
+		}
+	}
+	inv.logger.Info("caches purged after notification resync")
+}
+
+// Component returns a lifecycle component applying invalidations
+func (inv *Invalidator) Component() lifecycle.Component {
+	return lifecycle.Component{
+		Name:      "cache-invalidation",
+		DependsOn: []string{"notifications"},
+		Run:       inv.Run,
+	}
+}
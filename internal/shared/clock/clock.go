@@ -0,0 +1,127 @@
+// Package clock abstracts the time that business logic runs at. Order
+// expiry, trading sessions, token expiry and schedules read the time
+// from a Clock, so tests can use a Fake and replay tools can run the
+// whole system at a simulated time. Latencies, timeouts and retry
+// backoff measure real elapsed time and keep using the time package.
+package clock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+)
+
+// Clock tells the time and waits for it
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single-shot timer like time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a periodic timer like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// New returns the clock selected by configuration: the wall clock, or
+// with APP_SIMULATED_TIME a clock that starts at that time and runs at
+// real speed
+func New(cfg *config.AppConfig) (Clock, error) {
+	if cfg.SimulatedTime == "" {
+		return Real{}, nil
+	}
+	start, err := time.Parse(time.RFC3339, cfg.SimulatedTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APP_SIMULATED_TIME: %w", err)
+	}
+	return StartingAt(Real{}, start), nil
+}
+
+// Real is the wall clock
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (Real) Until(t time.Time) time.Duration        { return time.Until(t) }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (Real) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (Real) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// Offset shifts another clock by a fixed amount. Durations are not
+// scaled, so timers wait as long as on the base clock; the times they
+// deliver are base clock times.
+type Offset struct {
+	base   Clock
+	offset time.Duration
+}
+
+// NewOffset returns base shifted by offset
+func NewOffset(base Clock, offset time.Duration) *Offset {
+	return &Offset{base: base, offset: offset}
+}
+
+// StartingAt returns base shifted so that it reads start now
+func StartingAt(base Clock, start time.Time) *Offset {
+	return NewOffset(base, start.Sub(base.Now()))
+}
+
+func (o *Offset) Now() time.Time                         { return o.base.Now().Add(o.offset) }
+func (o *Offset) Since(t time.Time) time.Duration        { return o.Now().Sub(t) }
+func (o *Offset) Until(t time.Time) time.Duration        { return t.Sub(o.Now()) }
+func (o *Offset) After(d time.Duration) <-chan time.Time { return o.base.After(d) }
+func (o *Offset) NewTimer(d time.Duration) Timer         { return o.base.NewTimer(d) }
+func (o *Offset) NewTicker(d time.Duration) Ticker       { return o.base.NewTicker(d) }
+
+type contextKey struct{}
+
+// WithClock returns a context carrying c
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the clock set with WithClock, or the wall clock
+func FromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(contextKey{}).(Clock); ok {
+		return c
+	}
+	return Real{}
+}
+
+// Now returns the current time of the clock in ctx
+func Now(ctx context.Context) time.Time {
+	return FromContext(ctx).Now()
+}
+
+// Middleware puts c in every request's context
+func Middleware(c Clock) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithClock(r.Context(), c)))
+		})
+	}
+}
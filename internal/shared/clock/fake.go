@@ -0,0 +1,161 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a clock that only moves when told to. Timers and tickers fire
+// as Advance or Set passes their deadlines, in deadline order.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeTimer
+}
+
+// NewFake creates a fake clock reading start
+func NewFake(start time.Time) *Fake {
+	f := &Fake{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake time
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration { return f.Now().Sub(t) }
+func (f *Fake) Until(t time.Time) time.Duration { return t.Sub(f.Now()) }
+
+// After returns a channel that receives the time once it advances by d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer creates a timer firing once the time advances by d
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker creates a ticker firing every d of fake time
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive ticker interval")
+	}
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1), period: d}
+	t.Reset(d)
+	return fakeTicker{t}
+}
+
+// Advance moves the time forward by d, firing timers on the way
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.advanceTo(f.now.Add(d))
+}
+
+// Set moves the time to t. Moving forward fires timers on the way;
+// moving back fires nothing.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.Before(f.now) {
+		f.now = t
+		return
+	}
+	f.advanceTo(t)
+}
+
+// advanceTo fires due timers in deadline order; f.mu must be held
+func (f *Fake) advanceTo(target time.Time) {
+	for {
+		sort.Slice(f.waiters, func(i, j int) bool {
+			return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+		})
+		if len(f.waiters) == 0 || f.waiters[0].deadline.After(target) {
+			break
+		}
+		t := f.waiters[0]
+		f.now = t.deadline
+		// Like time.Ticker, a slow reader misses ticks instead of
+		// blocking the clock
+		select {
+		case t.c <- f.now:
+		default:
+		}
+		if t.period > 0 {
+			t.deadline = t.deadline.Add(t.period)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+	f.now = target
+}
+
+// BlockUntil waits until n timers or tickers are pending, so a test can
+// advance the time only once the code under test is waiting on it
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// remove drops a waiter and reports whether it was pending; f.mu must
+// be held
+func (f *Fake) remove(t *fakeTimer) bool {
+	for i, w := range f.waiters {
+		if w == t {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration // non-zero for tickers
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
+
+// fakeTicker adapts a periodic fakeTimer to Ticker
+type fakeTicker struct{ t *fakeTimer }
+
+func (t fakeTicker) C() <-chan time.Time { return t.t.c }
+func (t fakeTicker) Stop()               { t.t.Stop() }
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pending := f.remove(t)
+	t.deadline = f.now.Add(d)
+	if d <= 0 && t.period == 0 {
+		select {
+		case t.c <- f.now:
+		default:
+		}
+		return pending
+	}
+	f.waiters = append(f.waiters, t)
+	f.cond.Broadcast()
+	return pending
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"math/big"
+	"net"
 	"os"
 	"strconv"
 	"strings"
@@ -12,11 +14,17 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
-	CORS     CORSConfig
+	App        AppConfig
+	Database   DatabaseConfig
+	Server     ServerConfig
+	Admin      AdminConfig
+	OpenAPI    OpenAPIConfig
+	Lifecycle  LifecycleConfig
+	Jobs       JobsConfig
+	Trading    TradingConfig
+	Pagination PaginationConfig
+	JWT        JWTConfig
+	CORS       CORSConfig
 }
 
 // AppConfig holds application-level configuration
@@ -24,6 +32,11 @@ type AppConfig struct {
 	Environment string // development, staging, production
 	Port        int
 	LogLevel    string // debug, info, warn, error
+	NodeID      int    // 0-1023, unique per running instance; embedded in generated IDs
+
+	// SimulatedTime starts business time at an RFC 3339 instant instead
+	// of the wall clock, for integration tests and replays
+	SimulatedTime string
 }
 
 // DatabaseConfig holds database connection configuration
@@ -37,6 +50,22 @@ type DatabaseConfig struct {
 	MaxConnections     int
 	MaxIdleConnections int
 	ConnectionLifetime time.Duration
+
+	// Statements slower than SlowQueryThreshold are logged; 0 disables.
+	// LogAllQueries logs every statement at debug level.
+	SlowQueryThreshold time.Duration
+	LogAllQueries      bool
+
+	// Streaming replicas for read-only queries, as host or host:port
+	ReplicaHosts          []string
+	ReplicaMaxConnections int
+	ReplicaHealthInterval time.Duration
+	// ReplicaMaxLag takes a lagging replica out of rotation; 0 disables
+	ReplicaMaxLag time.Duration
+
+	// LeaderRenewInterval is how often leader election retries the lock
+	// and checks a held lock is still valid
+	LeaderRenewInterval time.Duration
 }
 
 // ServerConfig holds HTTP server configuration
@@ -45,6 +74,129 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration
 	IdleTimeout     time.Duration
 	ShutdownTimeout time.Duration
+	MaxBodySize     int64 // default request body limit in bytes
+	TLS             TLSConfig
+
+	H2C            bool   // serve HTTP/2 over cleartext for sidecar proxies
+	UnixSocket     string // optional Unix domain socket served alongside TCP
+	UnixSocketMode string // octal permissions of the socket file
+	SystemdSocket  bool   // use listeners from systemd socket activation instead of TCP
+}
+
+// TLSConfig holds HTTPS and mutual TLS configuration.
+// TLS is enabled when CertFile is set.
+type TLSConfig struct {
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string        // CA bundle used to verify client certificates
+	ClientAuth     string        // none, request, require
+	MinVersion     string        // 1.2, 1.3
+	ReloadInterval time.Duration // how often cert files are checked for changes
+}
+
+// Enabled returns true if TLS is configured
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// AdminConfig holds configuration for the internal admin listener
+// serving pprof, metrics and operational endpoints
+type AdminConfig struct {
+	Enabled      bool
+	Host         string // bind address; keep it internal
+	Port         int
+	WriteTimeout time.Duration // long enough for CPU profiles
+}
+
+// OpenAPIConfig holds API contract settings
+type OpenAPIConfig struct {
+	ServeDocs         bool // serve openapi.json and the docs page
+	ValidateRequests  bool // reject requests that don't match the spec
+	ValidateResponses bool // log responses that don't match the spec (development only)
+}
+
+// LifecycleConfig holds startup and shutdown coordination configuration
+type LifecycleConfig struct {
+	StartTimeout    time.Duration // default per-component start timeout
+	StopTimeout     time.Duration // default per-component stop timeout
+	ShutdownTimeout time.Duration // budget for the whole shutdown sequence
+}
+
+// JobsConfig holds background job queue configuration
+type JobsConfig struct {
+	Enabled      bool
+	Queues       []string      // queue:concurrency pairs, e.g. default:4
+	PollInterval time.Duration // fallback polling when no NOTIFY arrives
+	Timeout      time.Duration // per-job execution limit
+	RescueAfter  time.Duration // running jobs older than this are retried, or dead when out of attempts
+	Retention    time.Duration // finished jobs are deleted after this
+}
+
+// QueueConcurrency parses Queues into a map of queue name to workers
+func (c *JobsConfig) QueueConcurrency() (map[string]int, error) {
+	queues := make(map[string]int, len(c.Queues))
+	for _, entry := range c.Queues {
+		name, countStr, ok := strings.Cut(entry, ":")
+		count := 1
+		if ok {
+			n, err := strconv.Atoi(countStr)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid concurrency in %q", entry)
+			}
+			count = n
+		}
+		if name == "" {
+			return nil, fmt.Errorf("empty queue name in %q", entry)
+		}
+		queues[name] = count
+	}
+	return queues, nil
+}
+
+// TradingConfig holds trading defaults. Tenants can override any of
+// them; the JSON names are the keys in a tenant's settings.
+type TradingConfig struct {
+	MakerFeeBps      int      `json:"maker_fee_bps"`
+	TakerFeeBps      int      `json:"taker_fee_bps"`
+	Instruments      []string `json:"instruments"`        // tradable symbols; empty allows all
+	MaxOrderNotional string   `json:"max_order_notional"` // decimal, in quote currency
+	MaxOpenOrders    int      `json:"max_open_orders"`
+}
+
+// AllowsInstrument reports whether symbol may be traded
+func (c *TradingConfig) AllowsInstrument(symbol string) bool {
+	if len(c.Instruments) == 0 {
+		return true
+	}
+	for _, s := range c.Instruments {
+		if strings.EqualFold(s, symbol) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks trading settings on their own, e.g. after a tenant's
+// overrides are applied
+func (c *TradingConfig) Validate() error {
+	validator := NewValidator()
+	c.validate(validator)
+	return validator.Error()
+}
+
+func (c *TradingConfig) validate(validator *Validator) {
+	validator.Range("TRADING_MAKER_FEE_BPS", c.MakerFeeBps, -100, 1000)
+	validator.Range("TRADING_TAKER_FEE_BPS", c.TakerFeeBps, 0, 1000)
+	notional, ok := new(big.Rat).SetString(c.MaxOrderNotional)
+	validator.Assert(ok && notional.Sign() > 0, "TRADING_MAX_ORDER_NOTIONAL must be a positive decimal")
+	validator.Min("TRADING_MAX_OPEN_ORDERS", c.MaxOpenOrders, 1)
+}
+
+// PaginationConfig holds list endpoint defaults
+type PaginationConfig struct {
+	CursorSecret string // signs page cursors; random per process when empty
+	DefaultLimit int
+	MaxLimit     int
 }
 
 // JWTConfig holds JWT authentication configuration
@@ -68,26 +220,86 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		App: AppConfig{
-			Environment: getEnv("APP_ENV", "development"),
-			Port:        getEnvAsInt("APP_PORT", 8080),
-			LogLevel:    getEnv("APP_LOG_LEVEL", "info"),
+			Environment:   getEnv("APP_ENV", "development"),
+			Port:          getEnvAsInt("APP_PORT", 8080),
+			LogLevel:      getEnv("APP_LOG_LEVEL", "info"),
+			NodeID:        getEnvAsInt("APP_NODE_ID", 0),
+			SimulatedTime: getEnv("APP_SIMULATED_TIME", ""),
 		},
 		Database: DatabaseConfig{
 			Host:               getEnv("DB_HOST", "localhost"),
 			Port:               getEnvAsInt("DB_PORT", 5432),
 			Name:               getEnv("DB_NAME", "logistics_db"),
-			User:               getEnv("DB_USER", "postgres"),
+			User:               getEnv("DB_USER", "perfect_trade"),
 			Password:           getEnv("DB_PASSWORD", ""),
 			SSLMode:            getEnv("DB_SSL_MODE", "disable"),
 			MaxConnections:     getEnvAsInt("DB_MAX_CONNECTIONS", 25),
 			MaxIdleConnections: getEnvAsInt("DB_MAX_IDLE_CONNECTIONS", 5),
 			ConnectionLifetime: getEnvAsDuration("DB_CONNECTION_LIFETIME", 5*time.Minute),
+			SlowQueryThreshold: getEnvAsDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+			LogAllQueries:      getEnvAsBool("DB_LOG_ALL_QUERIES", false),
+
+			ReplicaHosts:          getEnvAsSlice("DB_REPLICA_HOSTS", nil),
+			ReplicaMaxConnections: getEnvAsInt("DB_REPLICA_MAX_CONNECTIONS", 25),
+			ReplicaHealthInterval: getEnvAsDuration("DB_REPLICA_HEALTH_INTERVAL", 5*time.Second),
+			ReplicaMaxLag:         getEnvAsDuration("DB_REPLICA_MAX_LAG", 30*time.Second),
+
+			LeaderRenewInterval: getEnvAsDuration("DB_LEADER_RENEW_INTERVAL", 5*time.Second),
 		},
 		Server: ServerConfig{
 			ReadTimeout:     getEnvAsDuration("SERVER_READ_TIMEOUT", 10*time.Second),
 			WriteTimeout:    getEnvAsDuration("SERVER_WRITE_TIMEOUT", 10*time.Second),
 			IdleTimeout:     getEnvAsDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
 			ShutdownTimeout: getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
+			MaxBodySize:     int64(getEnvAsInt("SERVER_MAX_BODY_SIZE", 1<<20)),
+			TLS: TLSConfig{
+				CertFile:       getEnv("SERVER_TLS_CERT_FILE", ""),
+				KeyFile:        getEnv("SERVER_TLS_KEY_FILE", ""),
+				ClientCAFile:   getEnv("SERVER_TLS_CLIENT_CA_FILE", ""),
+				ClientAuth:     getEnv("SERVER_TLS_CLIENT_AUTH", "none"),
+				MinVersion:     getEnv("SERVER_TLS_MIN_VERSION", "1.2"),
+				ReloadInterval: getEnvAsDuration("SERVER_TLS_RELOAD_INTERVAL", 30*time.Second),
+			},
+			H2C:            getEnvAsBool("SERVER_H2C", false),
+			UnixSocket:     getEnv("SERVER_UNIX_SOCKET", ""),
+			UnixSocketMode: getEnv("SERVER_UNIX_SOCKET_MODE", "0660"),
+			SystemdSocket:  getEnvAsBool("SERVER_SYSTEMD_SOCKET", false),
+		},
+		Admin: AdminConfig{
+			Enabled:      getEnvAsBool("ADMIN_ENABLED", true),
+			Host:         getEnv("ADMIN_HOST", "127.0.0.1"),
+			Port:         getEnvAsInt("ADMIN_PORT", 9090),
+			WriteTimeout: getEnvAsDuration("ADMIN_WRITE_TIMEOUT", 2*time.Minute),
+		},
+		OpenAPI: OpenAPIConfig{
+			ServeDocs:         getEnvAsBool("OPENAPI_SERVE_DOCS", true),
+			ValidateRequests:  getEnvAsBool("OPENAPI_VALIDATE_REQUESTS", true),
+			ValidateResponses: getEnvAsBool("OPENAPI_VALIDATE_RESPONSES", false),
+		},
+		Lifecycle: LifecycleConfig{
+			StartTimeout:    getEnvAsDuration("LIFECYCLE_START_TIMEOUT", 15*time.Second),
+			StopTimeout:     getEnvAsDuration("LIFECYCLE_STOP_TIMEOUT", 10*time.Second),
+			ShutdownTimeout: getEnvAsDuration("LIFECYCLE_SHUTDOWN_TIMEOUT", 45*time.Second),
+		},
+		Jobs: JobsConfig{
+			Enabled:      getEnvAsBool("JOBS_ENABLED", true),
+			Queues:       getEnvAsSlice("JOBS_QUEUES", []string{"default:4"}),
+			PollInterval: getEnvAsDuration("JOBS_POLL_INTERVAL", 5*time.Second),
+			Timeout:      getEnvAsDuration("JOBS_TIMEOUT", 5*time.Minute),
+			RescueAfter:  getEnvAsDuration("JOBS_RESCUE_AFTER", 15*time.Minute),
+			Retention:    getEnvAsDuration("JOBS_RETENTION", 7*24*time.Hour),
+		},
+		Trading: TradingConfig{
+			MakerFeeBps:      getEnvAsInt("TRADING_MAKER_FEE_BPS", 10),
+			TakerFeeBps:      getEnvAsInt("TRADING_TAKER_FEE_BPS", 20),
+			Instruments:      getEnvAsSlice("TRADING_INSTRUMENTS", nil),
+			MaxOrderNotional: getEnv("TRADING_MAX_ORDER_NOTIONAL", "1000000"),
+			MaxOpenOrders:    getEnvAsInt("TRADING_MAX_OPEN_ORDERS", 200),
+		},
+		Pagination: PaginationConfig{
+			CursorSecret: getEnv("PAGINATION_CURSOR_SECRET", ""),
+			DefaultLimit: getEnvAsInt("PAGINATION_DEFAULT_LIMIT", 50),
+			MaxLimit:     getEnvAsInt("PAGINATION_MAX_LIMIT", 500),
 		},
 		JWT: JWTConfig{
 			Secret: getEnv("JWT_SECRET", ""),
@@ -117,6 +329,12 @@ func (c *Config) Validate() error {
 	validator.OneOf("APP_ENV", c.App.Environment, []string{"development", "staging", "production"})
 	validator.Range("APP_PORT", c.App.Port, 1, 65535)
 	validator.OneOf("APP_LOG_LEVEL", c.App.LogLevel, []string{"debug", "info", "warn", "error"})
+	validator.Range("APP_NODE_ID", c.App.NodeID, 0, 1023)
+	if c.App.SimulatedTime != "" {
+		_, err := time.Parse(time.RFC3339, c.App.SimulatedTime)
+		validator.Assert(err == nil, "APP_SIMULATED_TIME must be an RFC 3339 timestamp")
+		validator.Assert(c.App.Environment != "production", "APP_SIMULATED_TIME is not allowed in production")
+	}
 
 	// Validate Database config
 	validator.Required("DB_HOST", c.Database.Host)
@@ -127,11 +345,74 @@ func (c *Config) Validate() error {
 	validator.OneOf("DB_SSL_MODE", c.Database.SSLMode, []string{"disable", "require", "verify-full"})
 	validator.Min("DB_MAX_CONNECTIONS", c.Database.MaxConnections, 1)
 	validator.Min("DB_MAX_IDLE_CONNECTIONS", c.Database.MaxIdleConnections, 1)
+	validator.Assert(c.Database.LeaderRenewInterval > 0, "DB_LEADER_RENEW_INTERVAL must be positive")
+	if len(c.Database.ReplicaHosts) > 0 {
+		for _, host := range c.Database.ReplicaHosts {
+			_, _, err := c.Database.ReplicaAddr(host)
+			validator.Assert(err == nil, fmt.Sprintf("DB_REPLICA_HOSTS: invalid replica %q", host))
+		}
+		validator.Min("DB_REPLICA_MAX_CONNECTIONS", c.Database.ReplicaMaxConnections, 1)
+		validator.Assert(c.Database.ReplicaHealthInterval > 0, "DB_REPLICA_HEALTH_INTERVAL must be positive")
+	}
+
+	// Validate TLS config
+	if c.Server.TLS.Enabled() {
+		validator.Required("SERVER_TLS_KEY_FILE", c.Server.TLS.KeyFile)
+		validator.OneOf("SERVER_TLS_MIN_VERSION", c.Server.TLS.MinVersion, []string{"1.2", "1.3"})
+		validator.OneOf("SERVER_TLS_CLIENT_AUTH", c.Server.TLS.ClientAuth, []string{"none", "request", "require"})
+		if c.Server.TLS.ClientAuth != "none" {
+			validator.Required("SERVER_TLS_CLIENT_CA_FILE", c.Server.TLS.ClientCAFile)
+		}
+	}
+
+	// Validate Server config
+	validator.Assert(c.Server.MaxBodySize > 0, "SERVER_MAX_BODY_SIZE must be positive")
+
+	// Validate listener config
+	if c.Server.UnixSocket != "" {
+		_, err := strconv.ParseUint(c.Server.UnixSocketMode, 8, 32)
+		validator.Assert(err == nil, "SERVER_UNIX_SOCKET_MODE must be an octal file mode")
+		// The socket is plaintext; it would bypass mandatory client certificates
+		validator.Assert(!c.Server.TLS.Enabled() || c.Server.TLS.ClientAuth != "require",
+			"SERVER_UNIX_SOCKET cannot be used with SERVER_TLS_CLIENT_AUTH=require")
+	}
+
+	// Validate Admin config
+	if c.Admin.Enabled {
+		validator.Required("ADMIN_HOST", c.Admin.Host)
+		validator.Range("ADMIN_PORT", c.Admin.Port, 1, 65535)
+		validator.Assert(c.Admin.Port != c.App.Port, "ADMIN_PORT must differ from APP_PORT")
+	}
+
+	// Validate Jobs config
+	if c.Jobs.Enabled {
+		_, err := c.Jobs.QueueConcurrency()
+		validator.Assert(err == nil, fmt.Sprintf("JOBS_QUEUES: %v", err))
+		validator.Assert(c.Jobs.PollInterval > 0, "JOBS_POLL_INTERVAL must be positive")
+		validator.Assert(c.Jobs.Timeout > 0, "JOBS_TIMEOUT must be positive")
+		validator.Assert(c.Jobs.RescueAfter > c.Jobs.Timeout, "JOBS_RESCUE_AFTER must exceed JOBS_TIMEOUT")
+	}
 
-	// Validate JWT config (if production)
+	// Validate Trading config
+	c.Trading.validate(validator)
+
+	// Validate Pagination config
+	validator.Min("PAGINATION_MAX_LIMIT", c.Pagination.MaxLimit, 1)
+	validator.Range("PAGINATION_DEFAULT_LIMIT", c.Pagination.DefaultLimit, 1, c.Pagination.MaxLimit)
+	// An inline comment after an empty value is read as the value
+	validator.Assert(!strings.HasPrefix(c.Pagination.CursorSecret, "#"),
+		"PAGINATION_CURSOR_SECRET looks like a comment; put comments on their own line")
+	if c.Pagination.CursorSecret != "" && !c.IsDevelopment() {
+		validator.MinLength("PAGINATION_CURSOR_SECRET", c.Pagination.CursorSecret, 32)
+	}
+
+	// Validate secrets (if production); every instance must share the
+	// cursor secret or pages break when requests hit another instance
 	if c.App.Environment == "production" {
 		validator.Required("JWT_SECRET", c.JWT.Secret)
 		validator.MinLength("JWT_SECRET", c.JWT.Secret, 32)
+		validator.Required("PAGINATION_CURSOR_SECRET", c.Pagination.CursorSecret)
+		validator.MinLength("PAGINATION_CURSOR_SECRET", c.Pagination.CursorSecret, 32)
 	}
 
 	return validator.Error()
@@ -150,6 +431,60 @@ func (c *DatabaseConfig) GetDatabaseURL() string {
 	)
 }
 
+// ReplicaAddr splits a replica entry into host and port, defaulting to
+// the primary port
+func (c *DatabaseConfig) ReplicaAddr(replica string) (string, int, error) {
+	if replica == "" {
+		return "", 0, fmt.Errorf("empty replica host")
+	}
+	host, portStr, err := net.SplitHostPort(replica)
+	if err != nil {
+		// No port given
+		return replica, c.Port, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid port in %q", replica)
+	}
+	return host, port, nil
+}
+
+// GetReplicaURL returns the connection string for a replica; credentials
+// and database name are shared with the primary
+func (c *DatabaseConfig) GetReplicaURL(replica string) (string, error) {
+	host, port, err := c.ReplicaAddr(replica)
+	if err != nil {
+		return "", err
+	}
+	replicaCfg := *c
+	replicaCfg.Host = host
+	replicaCfg.Port = port
+	return replicaCfg.GetDatabaseURL(), nil
+}
+
+// GetAddr returns the admin listener address
+func (c *AdminConfig) GetAddr() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// Redacted returns a copy of the configuration with secrets masked,
+// safe to expose on the admin listener
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Database.Password = redact(c.Database.Password)
+	redacted.JWT.Secret = redact(c.JWT.Secret)
+	redacted.Pagination.CursorSecret = redact(c.Pagination.CursorSecret)
+	return &redacted
+}
+
+// redact masks a secret while showing whether it is set
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
+
 // IsDevelopment returns true if running in development mode
 func (c *Config) IsDevelopment() bool {
 	return c.App.Environment == "development"
@@ -193,6 +528,18 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	return value
 }
 
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	valueStr := os.Getenv(key)
 	if valueStr == "" {
@@ -55,6 +55,13 @@ func (v *Validator) MinLength(key, value string, min int) {
 	}
 }
 
+// Assert records message if condition is false
+func (v *Validator) Assert(condition bool, message string) {
+	if !condition {
+		v.errors = append(v.errors, message)
+	}
+}
+
 // Error returns all validation errors as a single error
 func (v *Validator) Error() error {
 	if len(v.errors) == 0 {
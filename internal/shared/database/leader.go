@@ -0,0 +1,227 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+)
+
+var leaderGauge = metrics.NewGaugeVec(
+	"leader_election_is_leader",
+	"Whether this instance holds the leadership lock (1) or not (0).",
+	"election",
+)
+
+// LeaderStatus reports the state of an election
+type LeaderStatus struct {
+	Election string     `json:"election"`
+	Leader   bool       `json:"leader"`
+	Since    *time.Time `json:"since,omitempty"`
+}
+
+// Elector elects one leader among instances with a session-level
+// advisory lock held on a dedicated connection. The lock is released by
+// Postgres when the connection dies, so a crashed leader is replaced
+// after at most one renew interval.
+type Elector struct {
+	pool     *pgxpool.Pool
+	logger   logger.Logger
+	name     string
+	key      int64
+	interval time.Duration
+
+	mu        sync.Mutex
+	leader    bool
+	since     time.Time
+	callbacks []func(ctx context.Context)
+}
+
+// NewElector creates an elector for the named election. Instances
+// using the same name compete for the same lock.
+func NewElector(pool *pgxpool.Pool, name string, interval time.Duration, log logger.Logger) *Elector {
+	h := fnv.New64a()
+	h.Write([]byte("leader:" + name))
+
+	return &Elector{
+		pool:     pool,
+		logger:   log.With(logger.String("election", name)),
+		name:     name,
+		key:      int64(h.Sum64()),
+		interval: interval,
+	}
+}
+
+// OnElected registers fn to run whenever this instance becomes leader.
+// Its context is cancelled when leadership is lost or the elector stops;
+// fn must return promptly after that. Register callbacks before Run.
+func (e *Elector) OnElected(fn func(ctx context.Context)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.callbacks = append(e.callbacks, fn)
+}
+
+// IsLeader reports whether this instance currently holds the lock
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Status returns the current election state
+func (e *Elector) Status() LeaderStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	status := LeaderStatus{Election: e.name, Leader: e.leader}
+	if e.leader {
+		since := e.since
+		status.Since = &since
+	}
+	return status
+}
+
+// HealthDetails reports leadership for the admin health endpoint. Not
+// being leader is healthy.
+func (e *Elector) HealthDetails(ctx context.Context) (interface{}, error) {
+	return e.Status(), nil
+}
+
+// Run campaigns for leadership until ctx is cancelled
+func (e *Elector) Run(ctx context.Context) error {
+	leaderGauge.With(e.name).Set(0)
+
+	for {
+		if err := e.campaign(ctx); err != nil && ctx.Err() == nil {
+			e.logger.Warn("leader election connection failed", logger.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+// campaign holds one dedicated connection, trying for the lock every
+// interval and, once held, checking the session stays alive
+func (e *Elector) campaign(ctx context.Context) error {
+	pooled, err := e.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The lock lives as long as the session, so the connection must
+	// never go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		var acquired bool
+		if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&acquired); err != nil {
+			return err
+		}
+		if acquired {
+			return e.lead(ctx, conn)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead runs the callbacks while renewing the lock. It returns when the
+// session fails or ctx is cancelled, after the callbacks have returned;
+// the caller then closes the connection and campaigns again.
+func (e *Elector) lead(ctx context.Context, conn *pgx.Conn) error {
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+
+	e.mu.Lock()
+	e.leader = true
+	e.since = time.Now()
+	callbacks := append([]func(ctx context.Context){}, e.callbacks...)
+	e.mu.Unlock()
+
+	leaderGauge.With(e.name).Set(1)
+	e.logger.Info("became leader")
+
+	for _, fn := range callbacks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(leaderCtx)
+		}()
+	}
+
+	err := e.renew(ctx, conn)
+
+	cancel()
+	wg.Wait()
+
+	e.mu.Lock()
+	e.leader = false
+	e.mu.Unlock()
+	leaderGauge.With(e.name).Set(0)
+
+	if ctx.Err() != nil {
+		// Release explicitly so another instance takes over immediately
+		releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer releaseCancel()
+		_, _ = conn.Exec(releaseCtx, "SELECT pg_advisory_unlock($1)", e.key)
+		e.logger.Info("resigned leadership")
+		return nil
+	}
+
+	e.logger.Warn("lost leadership", logger.Error(err))
+	return nil
+}
+
+// renew pings the session every interval; a failed or slow ping means
+// the lock can no longer be trusted
+func (e *Elector) renew(ctx context.Context, conn *pgx.Conn) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, e.interval)
+		err := conn.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("leadership renewal failed: %w", err)
+		}
+	}
+}
+
+// Component returns a lifecycle component that campaigns while the
+// application runs and resigns on shutdown
+func (e *Elector) Component() lifecycle.Component {
+	return lifecycle.Component{
+		Name:      "leader-" + e.name,
+		DependsOn: []string{"database"},
+		Run:       e.Run,
+	}
+}
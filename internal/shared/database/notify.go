@@ -0,0 +1,316 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+)
+
+// maxPayloadSize is the Postgres NOTIFY payload limit
+const maxPayloadSize = 8000
+
+const (
+	minReconnectDelay = 500 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
+)
+
+var (
+	notificationsReceived = metrics.NewCounterVec(
+		"db_notifications_received_total",
+		"NOTIFY messages received by channel.",
+		"channel",
+	)
+	notificationsDropped = metrics.NewCounterVec(
+		"db_notifications_dropped_total",
+		"NOTIFY messages dropped because a subscriber was not keeping up.",
+		"channel",
+	)
+)
+
+// Notification is a message received on a channel. After the listener
+// reconnects, every subscriber gets one notification with Resync set:
+// messages sent while disconnected are lost, so state derived from the
+// channel should be rebuilt.
+type Notification struct {
+	Channel string
+	Payload string
+	Resync  bool
+}
+
+// Decode unmarshals a JSON notification payload
+func Decode[T any](n Notification) (T, error) {
+	var v T
+	if err := json.Unmarshal([]byte(n.Payload), &v); err != nil {
+		return v, fmt.Errorf("invalid payload on channel %s: %w", n.Channel, err)
+	}
+	return v, nil
+}
+
+// Subscription delivers notifications for one channel
+type Subscription struct {
+	C <-chan Notification
+
+	bus     *Bus
+	channel string
+	ch      chan Notification
+	once    sync.Once
+}
+
+// Close unsubscribes and closes C
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.unsubscribe(s)
+	})
+}
+
+// Bus is an in-process pub/sub backed by Postgres LISTEN/NOTIFY. One
+// dedicated connection listens on every subscribed channel and fans out
+// to subscribers; it reconnects and resubscribes after failures.
+type Bus struct {
+	pool   *pgxpool.Pool
+	logger logger.Logger
+
+	mu    sync.Mutex
+	subs  map[string]map[*Subscription]struct{}
+	wake  context.CancelFunc
+	dirty bool // subscriptions changed while not waiting
+}
+
+// NewBus creates a bus; call Run, or register Component, to start
+// listening
+func NewBus(pool *pgxpool.Pool, log logger.Logger) *Bus {
+	return &Bus{
+		pool:   pool,
+		logger: log,
+		subs:   make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe listens on channel. Notifications are dropped for this
+// subscriber when its buffer is full, so size it for bursts.
+func (b *Bus) Subscribe(channel string, buffer int) *Subscription {
+	ch := make(chan Notification, buffer)
+	sub := &Subscription{C: ch, bus: b, channel: channel, ch: ch}
+
+	b.mu.Lock()
+	if b.subs[channel] == nil {
+		b.subs[channel] = make(map[*Subscription]struct{})
+	}
+	b.subs[channel][sub] = struct{}{}
+	b.mu.Unlock()
+
+	b.interrupt()
+	return sub
+}
+
+// unsubscribe removes sub and closes its channel
+func (b *Bus) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	delete(b.subs[sub.channel], sub)
+	if len(b.subs[sub.channel]) == 0 {
+		delete(b.subs, sub.channel)
+	}
+	close(sub.ch)
+	b.mu.Unlock()
+
+	b.interrupt()
+}
+
+// interrupt wakes the listener so it updates its LISTEN set
+func (b *Bus) interrupt() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dirty = true
+	if b.wake != nil {
+		b.wake()
+	}
+}
+
+// Publish sends payload, JSON encoded, to channel. Inside WithTransaction
+// the notification is delivered only when the transaction commits.
+func (b *Bus) Publish(ctx context.Context, channel string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	if len(data) > maxPayloadSize {
+		return fmt.Errorf("notification payload for %s is %d bytes, limit is %d", channel, len(data), maxPayloadSize)
+	}
+
+	if _, err := GetQuerier(ctx, b.pool).Exec(ctx, "SELECT pg_notify($1, $2)", channel, string(data)); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Run listens until ctx is cancelled, reconnecting with exponential
+// backoff
+func (b *Bus) Run(ctx context.Context) error {
+	delay := minReconnectDelay
+	connected := false
+
+	for {
+		err := b.listen(ctx, connected, func() {
+			connected = true
+			delay = minReconnectDelay
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		b.logger.Warn("notification listener disconnected, reconnecting",
+			logger.Error(err),
+			logger.Duration("retry_in", delay),
+		)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// listen runs one listener connection until it fails. onConnect is
+// called once the channels are subscribed.
+func (b *Bus) listen(ctx context.Context, resync bool, onConnect func()) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// Take the connection out of the pool so LISTEN state never leaks
+	// to other users
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	listening := make(map[string]bool)
+	if err := b.syncChannels(ctx, conn, listening); err != nil {
+		return err
+	}
+	onConnect()
+	if resync {
+		b.broadcastResync()
+	}
+
+	for {
+		waitCtx, cancel := context.WithCancel(ctx)
+		b.mu.Lock()
+		if b.dirty {
+			// Subscriptions changed since the last sync
+			b.mu.Unlock()
+			cancel()
+			if err := b.syncChannels(ctx, conn, listening); err != nil {
+				return err
+			}
+			continue
+		}
+		b.wake = cancel
+		b.mu.Unlock()
+
+		n, err := conn.WaitForNotification(waitCtx)
+
+		b.mu.Lock()
+		b.wake = nil
+		b.mu.Unlock()
+		cancel()
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if waitCtx.Err() != nil {
+				// Woken by a subscription change
+				if err := b.syncChannels(ctx, conn, listening); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		b.dispatch(Notification{Channel: n.Channel, Payload: n.Payload})
+	}
+}
+
+// syncChannels issues LISTEN/UNLISTEN so the connection matches the
+// subscribed channels
+func (b *Bus) syncChannels(ctx context.Context, conn *pgx.Conn, listening map[string]bool) error {
+	b.mu.Lock()
+	b.dirty = false
+	wanted := make(map[string]bool, len(b.subs))
+	for channel := range b.subs {
+		wanted[channel] = true
+	}
+	b.mu.Unlock()
+
+	for channel := range wanted {
+		if listening[channel] {
+			continue
+		}
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", channel, err)
+		}
+		listening[channel] = true
+	}
+	for channel := range listening {
+		if wanted[channel] {
+			continue
+		}
+		if _, err := conn.Exec(ctx, "UNLISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return fmt.Errorf("failed to unlisten %s: %w", channel, err)
+		}
+		delete(listening, channel)
+	}
+	return nil
+}
+
+// dispatch fans a notification out to the channel's subscribers
+func (b *Bus) dispatch(n Notification) {
+	notificationsReceived.With(n.Channel).Inc()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subs[n.Channel] {
+		select {
+		case sub.ch <- n:
+		default:
+			notificationsDropped.With(n.Channel).Inc()
+			b.logger.Warn("notification subscriber is full, dropping message",
+				logger.String("channel", n.Channel),
+			)
+		}
+	}
+}
+
+// broadcastResync tells every subscriber that messages may have been lost
+func (b *Bus) broadcastResync() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for channel, subs := range b.subs {
+		for sub := range subs {
+			select {
+			case sub.ch <- Notification{Channel: channel, Resync: true}:
+			default:
+			}
+		}
+	}
+}
+
+// Component returns a lifecycle component running the listener. It
+// depends on the database component.
+func (b *Bus) Component() lifecycle.Component {
+	return lifecycle.Component{
+		Name:      "notifications",
+		DependsOn: []string{"database"},
+		Run:       b.Run,
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"time"
 
 	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// NewPostgresPool creates a new PostgreSQL connection pool
-func NewPostgresPool(ctx context.Context, cfg *config.DatabaseConfig) (*pgxpool.Pool, error) {
+// NewPostgresPool creates a new PostgreSQL connection pool. Statements are
+// traced for metrics and slow query logging.
+func NewPostgresPool(ctx context.Context, cfg *config.DatabaseConfig, log logger.Logger) (*pgxpool.Pool, error) {
 	// Build connection string
 	connStr := cfg.GetDatabaseURL()
 
@@ -27,6 +30,7 @@ func NewPostgresPool(ctx context.Context, cfg *config.DatabaseConfig) (*pgxpool.
 	poolConfig.MaxConnLifetime = cfg.ConnectionLifetime
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 	poolConfig.HealthCheckPeriod = 1 * time.Minute
+	poolConfig.ConnConfig.Tracer = NewQueryTracer(log, cfg.SlowQueryThreshold, cfg.LogAllQueries)
 
 	// Create connection pool
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
@@ -43,6 +47,25 @@ func NewPostgresPool(ctx context.Context, cfg *config.DatabaseConfig) (*pgxpool.
 	return pool, nil
 }
 
+// CheckRowSecurity fails when the connected role bypasses row-level
+// security. Superusers and BYPASSRLS roles see every tenant's rows even
+// on tables with FORCE ROW LEVEL SECURITY, so the application must
+// connect as an ordinary role.
+func CheckRowSecurity(ctx context.Context, pool *pgxpool.Pool) error {
+	var role string
+	var bypass bool
+	err := pool.QueryRow(ctx,
+		`SELECT current_user, rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`,
+	).Scan(&role, &bypass)
+	if err != nil {
+		return fmt.Errorf("failed to check database role: %w", err)
+	}
+	if bypass {
+		return fmt.Errorf("database role %q bypasses row-level security; tenant isolation is not enforced", role)
+	}
+	return nil
+}
+
 // Close closes the database connection pool
 func Close(pool *pgxpool.Pool) {
 	if pool != nil {
@@ -61,3 +84,49 @@ func HealthCheck(ctx context.Context, pool *pgxpool.Pool) error {
 
 	return nil
 }
+
+// PoolStats is a snapshot of connection pool usage
+type PoolStats struct {
+	TotalConns        int32  `json:"total_conns"`
+	IdleConns         int32  `json:"idle_conns"`
+	AcquiredConns     int32  `json:"acquired_conns"`
+	MaxConns          int32  `json:"max_conns"`
+	AcquireCount      int64  `json:"acquire_count"`
+	EmptyAcquireCount int64  `json:"empty_acquire_count"`
+	AcquireDuration   string `json:"acquire_duration"`
+}
+
+// HealthDetails performs a health check and reports pool statistics
+func HealthDetails(ctx context.Context, pool *pgxpool.Pool) (interface{}, error) {
+	return poolStats(pool), HealthCheck(ctx, pool)
+}
+
+// poolStats snapshots pool usage
+func poolStats(pool *pgxpool.Pool) PoolStats {
+	stat := pool.Stat()
+	return PoolStats{
+		TotalConns:        stat.TotalConns(),
+		IdleConns:         stat.IdleConns(),
+		AcquiredConns:     stat.AcquiredConns(),
+		MaxConns:          stat.MaxConns(),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+		AcquireDuration:   stat.AcquireDuration().String(),
+	}
+}
+
+// Component returns a lifecycle component that checks the pool on start
+// and closes it on stop. Other components should depend on it so the
+// pool outlives them.
+func Component(pool *pgxpool.Pool) lifecycle.Component {
+	return lifecycle.Component{
+		Name: "database",
+		OnStart: func(ctx context.Context) error {
+			return HealthCheck(ctx, pool)
+		},
+		OnStop: func(ctx context.Context) error {
+			Close(pool)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is the query interface shared by pools, connections and
+// transactions. Repositories depend on it instead of a concrete type.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// GetQuerier returns the transaction from context if there is one,
+// otherwise the pool. Repositories use it so the same code works inside
+// and outside WithTransaction. Tenant tables are only visible inside
+// WithTransaction or Cluster.ReadTransaction, where the tenant from
+// context is applied.
+func GetQuerier(ctx context.Context, pool *pgxpool.Pool) Querier {
+	if tx := GetTx(ctx); tx != nil {
+		return tx
+	}
+	return pool
+}
@@ -0,0 +1,287 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+)
+
+var replicaHealthy = metrics.NewGaugeVec(
+	"db_replica_healthy",
+	"Whether a read replica is in rotation (1) or not (0).",
+	"replica",
+)
+
+var replicaLag = metrics.NewGaugeVec(
+	"db_replica_lag_seconds",
+	"Replication replay lag of a read replica.",
+	"replica",
+)
+
+// replicaLagQuery reports replay lag, treating a fully caught-up replica
+// as zero lag even when the primary has been idle
+const replicaLagQuery = `
+	SELECT pg_is_in_recovery(),
+	       CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	            ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	       END`
+
+// replica is a read replica pool with its last health state
+type replica struct {
+	host    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+	lag     atomic.Int64 // nanoseconds
+	lastErr atomic.Value // string
+}
+
+// Cluster routes read-only queries to healthy replicas and everything
+// else to the primary. Without replicas it always uses the primary.
+type Cluster struct {
+	primary  *pgxpool.Pool
+	replicas []*replica
+	cfg      *config.DatabaseConfig
+	logger   logger.Logger
+	next     atomic.Uint64
+}
+
+// NewCluster creates replica pools next to an existing primary pool.
+// Replicas start out of rotation until their first health check passes,
+// so an unreachable replica doesn't prevent startup.
+func NewCluster(ctx context.Context, cfg *config.DatabaseConfig, primary *pgxpool.Pool, log logger.Logger) (*Cluster, error) {
+	c := &Cluster{
+		primary: primary,
+		cfg:     cfg,
+		logger:  log,
+	}
+
+	for _, host := range cfg.ReplicaHosts {
+		connStr, err := cfg.GetReplicaURL(host)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+
+		poolConfig, err := pgxpool.ParseConfig(connStr)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("unable to parse replica config for %s: %w", host, err)
+		}
+		poolConfig.MaxConns = int32(cfg.ReplicaMaxConnections)
+		poolConfig.MaxConnLifetime = cfg.ConnectionLifetime
+		poolConfig.MaxConnIdleTime = 30 * time.Minute
+		poolConfig.ConnConfig.Tracer = NewQueryTracer(log.With(logger.String("replica", host)), cfg.SlowQueryThreshold, cfg.LogAllQueries)
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("unable to create replica pool for %s: %w", host, err)
+		}
+		c.replicas = append(c.replicas, &replica{host: host, pool: pool})
+		replicaHealthy.With(host).Set(0)
+	}
+
+	c.checkReplicas(ctx)
+	return c, nil
+}
+
+// Primary returns the primary pool, for writes and transactions
+func (c *Cluster) Primary() *pgxpool.Pool {
+	return c.primary
+}
+
+// Reader returns a querier for read-only work. It is the transaction
+// from ctx if there is one, the primary if ctx was marked with
+// WithPrimary, otherwise a healthy replica chosen round-robin. With no
+// healthy replica it falls back to the primary.
+//
+// Outside a transaction no tenant is applied, so tenant tables show no
+// rows; read them inside ReadTransaction.
+func (c *Cluster) Reader(ctx context.Context) Querier {
+	if tx := GetTx(ctx); tx != nil {
+		return tx
+	}
+	return c.readPool(ctx)
+}
+
+// ReadTransaction runs fn in a read-only transaction on the pool Reader
+// would choose, with the tenant from ctx applied as in WithTransaction.
+// Inside an existing transaction fn runs in that one.
+func (c *Cluster) ReadTransaction(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if GetTx(ctx) != nil {
+		return fn(ctx)
+	}
+	return inTransaction(ctx, c.readPool(ctx), pgx.TxOptions{AccessMode: pgx.ReadOnly}, fn)
+}
+
+// readPool picks the pool for reads outside a transaction
+func (c *Cluster) readPool(ctx context.Context) *pgxpool.Pool {
+	if IsPrimaryPinned(ctx) || len(c.replicas) == 0 {
+		return c.primary
+	}
+
+	n := len(c.replicas)
+	start := int(c.next.Add(1) % uint64(n))
+	for i := 0; i < n; i++ {
+		r := c.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return c.primary
+}
+
+// Writer returns the transaction from ctx or the primary pool
+func (c *Cluster) Writer(ctx context.Context) Querier {
+	return GetQuerier(ctx, c.primary)
+}
+
+type primaryKey struct{}
+
+// WithPrimary pins reads made with ctx to the primary, for
+// read-your-writes flows that must see data they just committed
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// IsPrimaryPinned reports whether ctx was marked with WithPrimary
+func IsPrimaryPinned(ctx context.Context) bool {
+	pinned, _ := ctx.Value(primaryKey{}).(bool)
+	return pinned
+}
+
+// checkReplicas probes every replica and updates its rotation state
+func (c *Cluster) checkReplicas(ctx context.Context) {
+	for _, r := range c.replicas {
+		err := c.checkReplica(ctx, r)
+		healthy := err == nil
+		if was := r.healthy.Swap(healthy); was != healthy {
+			if healthy {
+				c.logger.Info("replica back in rotation", logger.String("replica", r.host))
+			} else {
+				c.logger.Warn("replica out of rotation",
+					logger.String("replica", r.host),
+					logger.Error(err),
+				)
+			}
+		}
+
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		r.lastErr.Store(errMsg)
+
+		if healthy {
+			replicaHealthy.With(r.host).Set(1)
+		} else {
+			replicaHealthy.With(r.host).Set(0)
+		}
+	}
+}
+
+// checkReplica verifies a replica is reachable, in recovery and within
+// the lag limit
+func (c *Cluster) checkReplica(ctx context.Context, r *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.ReplicaHealthInterval)
+	defer cancel()
+
+	var inRecovery bool
+	var lagSeconds float64
+	if err := r.pool.QueryRow(ctx, replicaLagQuery).Scan(&inRecovery, &lagSeconds); err != nil {
+		return fmt.Errorf("replica check failed: %w", err)
+	}
+	if !inRecovery {
+		// A promoted replica accepts writes and no longer follows the
+		// primary; keep reads off it until the topology is fixed
+		return fmt.Errorf("replica is not in recovery")
+	}
+
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	r.lag.Store(int64(lag))
+	replicaLag.With(r.host).Set(lagSeconds)
+
+	if c.cfg.ReplicaMaxLag > 0 && lag > c.cfg.ReplicaMaxLag {
+		return fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Millisecond), c.cfg.ReplicaMaxLag)
+	}
+	return nil
+}
+
+// ReplicaStatus is the health of one replica
+type ReplicaStatus struct {
+	Host    string    `json:"host"`
+	Healthy bool      `json:"healthy"`
+	Lag     string    `json:"lag"`
+	Error   string    `json:"error,omitempty"`
+	Pool    PoolStats `json:"pool"`
+}
+
+// HealthDetails reports the primary health and each replica's state.
+// Unhealthy replicas don't fail the check since reads fall back to the
+// primary.
+func (c *Cluster) HealthDetails(ctx context.Context) (interface{}, error) {
+	primary, err := HealthDetails(ctx, c.primary)
+
+	replicas := make([]ReplicaStatus, 0, len(c.replicas))
+	for _, r := range c.replicas {
+		errMsg, _ := r.lastErr.Load().(string)
+		replicas = append(replicas, ReplicaStatus{
+			Host:    r.host,
+			Healthy: r.healthy.Load(),
+			Lag:     time.Duration(r.lag.Load()).String(),
+			Error:   errMsg,
+			Pool:    poolStats(r.pool),
+		})
+	}
+
+	return map[string]interface{}{
+		"primary":  primary,
+		"replicas": replicas,
+	}, err
+}
+
+// Close closes the replica pools; the primary is owned by the caller
+func (c *Cluster) Close() {
+	for _, r := range c.replicas {
+		r.pool.Close()
+	}
+}
+
+// Component returns a lifecycle component that probes replicas every
+// DB_REPLICA_HEALTH_INTERVAL and closes their pools on stop. It depends
+// on the primary database component.
+func (c *Cluster) Component() lifecycle.Component {
+	return lifecycle.Component{
+		Name:      "database-replicas",
+		DependsOn: []string{"database"},
+		Run: func(ctx context.Context) error {
+			if len(c.replicas) == 0 {
+				return nil
+			}
+
+			ticker := time.NewTicker(c.cfg.ReplicaHealthInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+					c.checkReplicas(ctx)
+				}
+			}
+		},
+		OnStop: func(ctx context.Context) error {
+			c.Close()
+			return nil
+		},
+	}
+}
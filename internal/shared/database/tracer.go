@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+)
+
+var (
+	queryDuration = metrics.NewHistogramVec(
+		"db_query_duration_seconds",
+		"SQL statement latency by statement name.",
+		[]float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+		"statement",
+	)
+	queryErrors = metrics.NewCounterVec(
+		"db_query_errors_total",
+		"SQL statements that returned an error, by statement name.",
+		"statement",
+	)
+	slowQueries = metrics.NewCounterVec(
+		"db_slow_queries_total",
+		"SQL statements slower than DB_SLOW_QUERY_THRESHOLD, by statement name.",
+		"statement",
+	)
+)
+
+type queryNameKey struct{}
+
+// WithQueryName names the statements run with ctx in logs and metrics,
+// e.g. "orders.list". Unnamed statements are labelled from their SQL.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// QueryTracer is a pgx.QueryTracer that records latency per statement
+// and logs slow statements. Argument values are never logged, only
+// their types.
+type QueryTracer struct {
+	logger        logger.Logger
+	slowThreshold time.Duration
+	logAll        bool
+}
+
+// NewQueryTracer creates a tracer. A zero slowThreshold disables slow
+// query logging; logAll logs every statement at debug level.
+func NewQueryTracer(log logger.Logger, slowThreshold time.Duration, logAll bool) *QueryTracer {
+	return &QueryTracer{
+		logger:        log,
+		slowThreshold: slowThreshold,
+		logAll:        logAll,
+	}
+}
+
+type traceKey struct{}
+
+// queryTrace is carried in the context from TraceQueryStart to TraceQueryEnd
+type queryTrace struct {
+	start time.Time
+	sql   string
+	args  []string
+}
+
+// TraceQueryStart implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, &queryTrace{
+		start: time.Now(),
+		sql:   data.SQL,
+		args:  redactArgs(data.Args),
+	})
+}
+
+// TraceQueryEnd implements pgx.QueryTracer
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(traceKey{}).(*queryTrace)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(trace.start)
+
+	name, _ := ctx.Value(queryNameKey{}).(string)
+	if name == "" {
+		name = statementName(trace.sql)
+	}
+
+	queryDuration.With(name).ObserveDuration(elapsed)
+	if data.Err != nil {
+		queryErrors.With(name).Inc()
+	}
+
+	slow := t.slowThreshold > 0 && elapsed >= t.slowThreshold
+	if slow {
+		slowQueries.With(name).Inc()
+	}
+	if !slow && !t.logAll {
+		return
+	}
+
+	fields := []logger.Field{
+		logger.String("statement", name),
+		logger.String("sql", compactSQL(trace.sql)),
+		logger.Any("args", trace.args),
+		logger.Duration("duration", elapsed),
+		logger.Int64("rows", data.CommandTag.RowsAffected()),
+	}
+	if reqID := middleware.GetReqID(ctx); reqID != "" {
+		fields = append(fields, logger.String("request_id", reqID))
+	}
+	if data.Err != nil {
+		fields = append(fields, logger.Error(data.Err))
+	}
+
+	if slow {
+		t.logger.Warn("slow query", fields...)
+	} else {
+		t.logger.Debug("query", fields...)
+	}
+}
+
+// redactArgs replaces argument values with their types
+func redactArgs(args []any) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			redacted[i] = "NULL"
+			continue
+		}
+		redacted[i] = fmt.Sprintf("<%T>", arg)
+	}
+	return redacted
+}
+
+var (
+	whitespacePattern = regexp.MustCompile(`\s+`)
+	literalPattern    = regexp.MustCompile(`'(?:[^']|'')*'`)
+	tablePattern      = regexp.MustCompile(`(?i)\b(?:FROM|INTO|UPDATE|JOIN)\s+([a-z_][a-z0-9_.]*)`)
+)
+
+// compactSQL masks string literals and collapses whitespace so
+// statements log on one line without inline values
+func compactSQL(sql string) string {
+	sql = literalPattern.ReplaceAllString(sql, "'?'")
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(sql, " "))
+}
+
+// statementName derives a low-cardinality label like "select instruments"
+// from the verb and first table of a statement
+func statementName(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	verb := strings.ToLower(fields[0])
+	for _, m := range tablePattern.FindAllStringSubmatchIndex(sql, -1) {
+		// Skip function calls such as EXTRACT(EPOCH FROM now())
+		if strings.HasPrefix(sql[m[1]:], "(") && !strings.EqualFold(sql[m[0]:m[0]+4], "INTO") {
+			continue
+		}
+		return verb + " " + strings.ToLower(sql[m[2]:m[3]])
+	}
+	return verb
+}
@@ -30,9 +30,20 @@ func NewPostgresTransactionManager(pool *pgxpool.Pool) *PostgresTransactionManag
 func (tm *PostgresTransactionManager) WithTransaction(
 	ctx context.Context,
 	fn func(ctx context.Context) (interface{}, error),
+) (interface{}, error) {
+	return inTransaction(ctx, tm.pool, pgx.TxOptions{}, fn)
+}
+
+// inTransaction runs fn in a transaction on pool with the tenant from
+// ctx applied
+func inTransaction(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	opts pgx.TxOptions,
+	fn func(ctx context.Context) (interface{}, error),
 ) (interface{}, error) {
 	// Begin transaction
-	tx, err := tm.pool.Begin(ctx)
+	tx, err := pool.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -45,6 +56,15 @@ func (tm *PostgresTransactionManager) WithTransaction(
 		}
 	}()
 
+	// Scope row-level security to the tenant for this transaction only;
+	// without a tenant, tenant tables show no rows
+	if tenantID, ok := TenantID(ctx); ok {
+		if _, err := tx.Exec(ctx, "SELECT set_config('app.tenant_id', $1, true)", tenantID); err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, fmt.Errorf("failed to set tenant: %w", err)
+		}
+	}
+
 	// Add transaction to context
 	txCtx := context.WithValue(ctx, txKey, tx)
 
@@ -69,7 +89,10 @@ func (tm *PostgresTransactionManager) WithTransaction(
 // Context key for transaction
 type contextKey string
 
-const txKey contextKey = "tx"
+const (
+	txKey     contextKey = "tx"
+	tenantKey contextKey = "tenant"
+)
 
 // GetTx retrieves the transaction from context
 func GetTx(ctx context.Context) pgx.Tx {
@@ -79,3 +102,25 @@ func GetTx(ctx context.Context) pgx.Tx {
 	}
 	return tx
 }
+
+// WithoutTx returns ctx without its transaction, for work that outlives
+// the transaction or is shared with other callers
+func WithoutTx(ctx context.Context) context.Context {
+	if GetTx(ctx) == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, txKey, nil)
+}
+
+// WithTenantID scopes transactions started with ctx to a tenant.
+// Callers use tenant.WithID; it lives here so WithTransaction can read it
+// without importing the tenant package.
+func WithTenantID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey, id)
+}
+
+// TenantID returns the tenant set with WithTenantID
+func TenantID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,303 @@
+// Package decimal implements exact decimal numbers for prices,
+// quantities and balances. Values never pass through float64: they are
+// parsed from and written as decimal strings, and stored as Postgres
+// NUMERIC.
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode selects how digits beyond the target scale are dropped
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest value, ties to the even neighbour
+	// (banker's rounding)
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest value, ties away from zero
+	HalfUp
+	// HalfDown rounds to the nearest value, ties towards zero
+	HalfDown
+	// Up rounds away from zero
+	Up
+	// Down rounds towards zero (truncation)
+	Down
+	// Ceiling rounds towards positive infinity
+	Ceiling
+	// Floor rounds towards negative infinity
+	Floor
+)
+
+// maxLength bounds parsed input so hostile strings can't force huge
+// allocations
+const maxLength = 100
+
+// ErrDivisionByZero is returned by Div for a zero divisor
+var ErrDivisionByZero = errors.New("decimal division by zero")
+
+// Decimal is an arbitrary-precision decimal number: coef × 10^-scale.
+// The zero value is 0. Decimals are immutable; operations return new
+// values, so they are safe to copy and share.
+type Decimal struct {
+	coef  *big.Int // nil means zero; never modified once set
+	scale int32    // digits after the decimal point, >= 0
+}
+
+// Zero is the decimal 0
+var Zero = Decimal{}
+
+var ten = big.NewInt(10)
+
+// New returns unscaled × 10^-scale, e.g. New(12345, 2) is 123.45
+func New(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		panic("decimal: negative scale")
+	}
+	return Decimal{coef: big.NewInt(unscaled), scale: scale}
+}
+
+// NewFromInt returns the integer n
+func NewFromInt(n int64) Decimal {
+	return New(n, 0)
+}
+
+// Parse reads a plain decimal string such as "-12.340". Exponents,
+// fractions and surrounding spaces are rejected. Trailing zeros are kept
+// as scale.
+func Parse(s string) (Decimal, error) {
+	if len(s) > maxLength {
+		return Decimal{}, fmt.Errorf("decimal: %d characters exceeds the limit of %d", len(s), maxLength)
+	}
+	digits := s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	intPart, fracPart, hasPoint := strings.Cut(digits, ".")
+	if intPart == "" || (hasPoint && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("decimal: invalid number %q", s)
+	}
+
+	coef, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	if s[0] == '-' {
+		coef.Neg(coef)
+	}
+	return Decimal{coef: coef, scale: int32(len(fracPart))}, nil
+}
+
+// MustParse is Parse for constants; it panics on invalid input
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// int returns the coefficient; callers must not modify it
+func (d Decimal) int() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// String returns the plain decimal form, keeping the scale: 1.50 stays
+// "1.50"
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		point := len(digits) - int(d.scale)
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Scale returns the number of digits after the decimal point
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero reports whether d is 0
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares values regardless of scale: 1.5 equals 1.50
+func (d Decimal) Cmp(other Decimal) int {
+	a, b := align(d, other)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and other have the same value
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// align returns both coefficients at the larger scale
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	switch {
+	case a.scale < b.scale:
+		return rescale(a.int(), b.scale-a.scale), b.int()
+	case a.scale > b.scale:
+		return a.int(), rescale(b.int(), a.scale-b.scale)
+	default:
+		return a.int(), b.int()
+	}
+}
+
+// rescale multiplies n by 10^digits into a new integer
+func rescale(n *big.Int, digits int32) *big.Int {
+	return new(big.Int).Mul(n, pow10(digits))
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.int()), scale: d.scale}
+}
+
+// Abs returns |d|
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.int()), scale: d.scale}
+}
+
+// Add returns d + other at the larger of the two scales
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{coef: new(big.Int).Add(a, b), scale: max(d.scale, other.scale)}
+}
+
+// Sub returns d - other at the larger of the two scales
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b := align(d, other)
+	return Decimal{coef: new(big.Int).Sub(a, b), scale: max(d.scale, other.scale)}
+}
+
+// Mul returns the exact product; its scale is the sum of the scales
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.int(), other.int()), scale: d.scale + other.scale}
+}
+
+// Div returns d / other rounded to scale digits. Division is the one
+// operation that can't be exact, so the caller picks the precision.
+func (d Decimal) Div(other Decimal, scale int32, mode RoundingMode) (Decimal, error) {
+	if other.IsZero() {
+		return Decimal{}, ErrDivisionByZero
+	}
+	if scale < 0 {
+		return Decimal{}, fmt.Errorf("decimal: negative scale %d", scale)
+	}
+	// d/other × 10^scale = coef × 10^(other.scale+scale) / (otherCoef × 10^d.scale)
+	num := rescale(d.int(), other.scale+scale)
+	den := rescale(other.int(), d.scale)
+	return Decimal{coef: quo(num, den, mode), scale: scale}, nil
+}
+
+// Round returns d with exactly scale digits after the point. Increasing
+// the scale is exact; decreasing it rounds with mode.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale < 0 {
+		panic("decimal: negative scale")
+	}
+	if scale >= d.scale {
+		return Decimal{coef: rescale(d.int(), scale-d.scale), scale: scale}
+	}
+	return Decimal{coef: quo(d.int(), pow10(d.scale-scale), mode), scale: scale}
+}
+
+// Truncate drops digits beyond scale
+func (d Decimal) Truncate(scale int32) Decimal {
+	return d.Round(scale, Down)
+}
+
+// Normalize removes trailing zeros after the point: 1.500 becomes 1.5
+func (d Decimal) Normalize() Decimal {
+	coef, scale := new(big.Int).Set(d.int()), d.scale
+	r := new(big.Int)
+	for scale > 0 {
+		q, rem := new(big.Int).QuoRem(coef, ten, r)
+		if rem.Sign() != 0 {
+			break
+		}
+		coef, scale = q, scale-1
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// IsMultipleOf reports whether d is an integer multiple of step, e.g. a
+// price on the tick grid. It is false for a zero step.
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	if step.IsZero() {
+		return false
+	}
+	a, b := align(d, step)
+	return new(big.Int).Rem(a, b).Sign() == 0
+}
+
+// quo divides num by den, rounding the quotient with mode
+func quo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// QuoRem truncates towards zero; decide whether to step away from it
+	sign := num.Sign() * den.Sign()
+	var away bool
+	switch mode {
+	case Up:
+		away = true
+	case Down:
+		away = false
+	case Ceiling:
+		away = sign > 0
+	case Floor:
+		away = sign < 0
+	default:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch c := half.Cmp(new(big.Int).Abs(den)); {
+		case c > 0:
+			away = true
+		case c < 0:
+			away = false
+		case mode == HalfUp:
+			away = true
+		case mode == HalfDown:
+			away = false
+		default:
+			away = q.Bit(0) == 1
+		}
+	}
+	if away {
+		q.Add(q, big.NewInt(int64(sign)))
+	}
+	return q
+}
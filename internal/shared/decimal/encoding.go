@@ -0,0 +1,90 @@
+package decimal
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/openapi"
+)
+
+// MarshalJSON writes d as a JSON string so clients never parse it as a
+// float
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON reads a JSON string. Numbers are rejected: by the time
+// most clients serialize them they have been through a float.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return &json.UnmarshalTypeError{Value: "number", Type: reflect.TypeOf(Decimal{})}
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: "string " + strconv.Quote(s), Type: reflect.TypeOf(Decimal{})}
+	}
+	*d = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, e.g. for CSV and query
+// parameters
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// OpenAPISchema implements openapi.SchemaProvider
+func (Decimal) OpenAPISchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type:    "string",
+		Format:  "decimal",
+		Pattern: `^[+-]?[0-9]+(\.[0-9]+)?$`,
+		Example: "123.45",
+	}
+}
+
+// ScanNumeric implements pgtype.NumericScanner. Scan nullable columns
+// into *Decimal.
+func (d *Decimal) ScanNumeric(n pgtype.Numeric) error {
+	switch {
+	case !n.Valid:
+		return fmt.Errorf("cannot scan NULL into decimal.Decimal")
+	case n.NaN || n.InfinityModifier != pgtype.Finite:
+		return fmt.Errorf("cannot scan non-finite NUMERIC into decimal.Decimal")
+	}
+
+	coef := new(big.Int)
+	if n.Int != nil {
+		coef.Set(n.Int)
+	}
+	if n.Exp > 0 {
+		*d = Decimal{coef: rescale(coef, n.Exp)}
+		return nil
+	}
+	*d = Decimal{coef: coef, scale: -n.Exp}
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer
+func (d Decimal) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: new(big.Int).Set(d.int()), Exp: -d.scale, Valid: true}, nil
+}
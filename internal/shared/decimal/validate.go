@@ -0,0 +1,86 @@
+package decimal
+
+import (
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// Error codes returned by this package, used as field error codes
+const (
+	CodeNotPositive = "FIELD_NOT_POSITIVE"
+	CodeScale       = "FIELD_SCALE"
+	CodeOffTick     = "PRICE_OFF_TICK"
+	CodeOffLot      = "QUANTITY_OFF_LOT"
+)
+
+func init() {
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeNotPositive,
+		Category:    apperrors.ErrValidation,
+		Message:     "This field must be greater than zero",
+		Description: "A price, quantity or amount is zero or negative",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeScale,
+		Category:    apperrors.ErrValidation,
+		Message:     "This field must have at most {scale} decimal places",
+		Description: "A number is more precise than the instrument allows",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeOffTick,
+		Category:    apperrors.ErrValidation,
+		Message:     "This field must be a multiple of the tick size {tick}",
+		Description: "A price is not on the instrument's tick grid",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeOffLot,
+		Category:    apperrors.ErrValidation,
+		Message:     "This field must be a multiple of the lot size {lot}",
+		Description: "A quantity is not a whole number of the instrument's lots",
+	})
+}
+
+// CheckPositive records a field error on ve unless d > 0. It reports
+// whether d passed.
+func CheckPositive(ve *apperrors.ValidationError, field string, d Decimal) bool {
+	if d.Sign() <= 0 {
+		ve.AddCode(field, CodeNotPositive, nil)
+		return false
+	}
+	return true
+}
+
+// CheckScale records a field error on ve if d has more significant
+// decimal places than scale; trailing zeros don't count
+func CheckScale(ve *apperrors.ValidationError, field string, d Decimal, scale int32) bool {
+	if d.Normalize().Scale() > scale {
+		ve.AddCode(field, CodeScale, map[string]interface{}{"scale": scale})
+		return false
+	}
+	return true
+}
+
+// CheckTick records a field error on ve unless price is positive and a
+// multiple of tick
+func CheckTick(ve *apperrors.ValidationError, field string, price, tick Decimal) bool {
+	if !CheckPositive(ve, field, price) {
+		return false
+	}
+	if !price.IsMultipleOf(tick) {
+		ve.AddCode(field, CodeOffTick, map[string]interface{}{"tick": tick.String()})
+		return false
+	}
+	return true
+}
+
+// CheckLot records a field error on ve unless quantity is positive and a
+// multiple of lot
+func CheckLot(ve *apperrors.ValidationError, field string, quantity, lot Decimal) bool {
+	if !CheckPositive(ve, field, quantity) {
+		return false
+	}
+	if !quantity.IsMultipleOf(lot) {
+		ve.AddCode(field, CodeOffLot, map[string]interface{}{"lot": lot.String()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AppError is a domain error carrying a machine-readable code and
+// structured context. Its category is one of the package sentinels, so
+// Is(err, ErrBusinessRule) and friends keep working.
+type AppError struct {
+	// Category is the sentinel this error belongs to (e.g. ErrBusinessRule)
+	Category error
+	// Code is a stable machine-readable reason (e.g. INSUFFICIENT_FUNDS)
+	Code string
+	// Detail is a human-readable message that is safe to send to clients
+	Detail string
+	// Internal is extra context for logs only, never sent to clients
+	Internal string
+	// Metadata holds structured details (e.g. required and available balance)
+	Metadata map[string]interface{}
+	// Retryable reports whether the client may retry the same request
+	Retryable bool
+	// Cause is the underlying error, if any
+	Cause error
+}
+
+// New creates an AppError in the given category
+func New(category error, code, detail string) *AppError {
+	return &AppError{
+		Category: category,
+		Code:     code,
+		Detail:   detail,
+	}
+}
+
+// Newf creates an AppError with a formatted client detail
+func Newf(category error, code, format string, args ...interface{}) *AppError {
+	return New(category, code, fmt.Sprintf(format, args...))
+}
+
+// WithCause sets the underlying error
+func (e *AppError) WithCause(err error) *AppError {
+	e.Cause = err
+	return e
+}
+
+// WithInternal sets log-only context
+func (e *AppError) WithInternal(format string, args ...interface{}) *AppError {
+	e.Internal = fmt.Sprintf(format, args...)
+	return e
+}
+
+// WithMeta adds a metadata entry
+func (e *AppError) WithMeta(key string, value interface{}) *AppError {
+	if e.Metadata == nil {
+		e.Metadata = make(map[string]interface{})
+	}
+	e.Metadata[key] = value
+	return e
+}
+
+// AsRetryable marks the error as retryable
+func (e *AppError) AsRetryable() *AppError {
+	e.Retryable = true
+	return e
+}
+
+// Error returns the full error text for logs, including internal detail
+func (e *AppError) Error() string {
+	parts := make([]string, 0, 4)
+	if e.Code != "" {
+		parts = append(parts, e.Code)
+	}
+	if e.Detail != "" {
+		parts = append(parts, e.Detail)
+	} else if e.Category != nil {
+		parts = append(parts, e.Category.Error())
+	}
+	if e.Internal != "" {
+		parts = append(parts, e.Internal)
+	}
+	if e.Cause != nil {
+		parts = append(parts, e.Cause.Error())
+	}
+	return strings.Join(parts, ": ")
+}
+
+// Unwrap exposes both the category and the cause to Is and As
+func (e *AppError) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.Category != nil {
+		errs = append(errs, e.Category)
+	}
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	return errs
+}
+
+// AsAppError finds the first AppError in err's chain
+func AsAppError(err error) (*AppError, bool) {
+	var appErr *AppError
+	if As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// IsRetryable reports whether err is marked as retryable
+func IsRetryable(err error) bool {
+	appErr, ok := AsAppError(err)
+	return ok && appErr.Retryable
+}
+
+// CodeOf returns the AppError code in err's chain, or an empty string
+func CodeOf(err error) string {
+	if appErr, ok := AsAppError(err); ok {
+		return appErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package errors
+
+import "net/http"
+
+// Built-in error codes returned for the package sentinels
+const (
+	CodeValidation         = "VALIDATION_ERROR"
+	CodeNotFound           = "NOT_FOUND"
+	CodeConflict           = "CONFLICT"
+	CodeInvalidInput       = "INVALID_INPUT"
+	CodeBusinessRule       = "BUSINESS_RULE_VIOLATION"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	CodeRequestTimeout     = "REQUEST_TIMEOUT"
+)
+
+// Built-in field-level validation codes
+const (
+	CodeFieldRequired = "FIELD_REQUIRED"
+	CodeFieldInvalid  = "FIELD_INVALID"
+	CodeFieldTooLong  = "FIELD_TOO_LONG"
+	CodeFieldRange    = "FIELD_OUT_OF_RANGE"
+)
+
+// registerBuiltinCodes registers the codes owned by this package
+func registerBuiltinCodes(r *Registry) {
+	// Category codes
+	r.Register(CodeDefinition{
+		Code:        CodeValidation,
+		Category:    ErrValidation,
+		Message:     "The request contains invalid fields",
+		Description: "One or more fields failed validation; see the errors array",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeNotFound,
+		Category:    ErrNotFound,
+		Message:     "The requested resource was not found",
+		Description: "The resource does not exist or is not visible to the caller",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeConflict,
+		Category:    ErrAlreadyExists,
+		Message:     "The resource already exists",
+		Description: "A resource with the same identity already exists",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeInvalidInput,
+		Category:    ErrInvalidInput,
+		Message:     "The request is malformed",
+		Description: "The request body or parameters could not be parsed",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeBusinessRule,
+		Category:    ErrBusinessRule,
+		Message:     "The request violates a business rule",
+		Description: "The request is well-formed but not allowed in the current state",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeUnauthorized,
+		Category:    ErrUnauthorized,
+		Message:     "Authentication required",
+		Description: "Credentials are missing, invalid or expired",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeForbidden,
+		Category:    ErrForbidden,
+		Message:     "Access denied",
+		Description: "The caller is authenticated but lacks permission",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeInternal,
+		Category:    ErrInternal,
+		Message:     "An internal error occurred",
+		Description: "An unexpected server-side failure",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeServiceUnavailable,
+		Category:    ErrExternal,
+		Status:      http.StatusServiceUnavailable,
+		Message:     "The service is temporarily unavailable, please retry",
+		Description: "A transient failure; the request may be retried",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeRequestTimeout,
+		Category:    ErrExternal,
+		Status:      http.StatusServiceUnavailable,
+		Message:     "The request took too long to process, please retry",
+		Description: "The route's handler timeout expired",
+	})
+
+	// Field-level codes
+	r.Register(CodeDefinition{
+		Code:        CodeFieldRequired,
+		Category:    ErrValidation,
+		Message:     "This field is required",
+		Description: "A required field is missing or empty",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeFieldInvalid,
+		Category:    ErrValidation,
+		Message:     "This field has an invalid value",
+		Description: "A field value has the wrong format or is not allowed",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeFieldTooLong,
+		Category:    ErrValidation,
+		Message:     "This field must be at most {max} characters",
+		Description: "A string field exceeds its maximum length",
+	})
+	r.Register(CodeDefinition{
+		Code:        CodeFieldRange,
+		Category:    ErrValidation,
+		Message:     "This field must be between {min} and {max}",
+		Description: "A numeric field is outside its allowed range",
+	})
+}
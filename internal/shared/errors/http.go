@@ -1,61 +1,174 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/text/language"
+
 	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
 )
 
-// ErrorResponse is the JSON error response
+// ErrorResponse is the legacy JSON error response.
+// It is served to clients that negotiate application/json.
 type ErrorResponse struct {
 	Error string `json:"error"`
 	Code  string `json:"code,omitempty"`
 }
 
-// WriteError writes an error as JSON response
+// WriteError writes an error as an RFC 7807 problem+json response
 func WriteError(w http.ResponseWriter, r *http.Request, err error, log logger.Logger) {
 	// Determine status code and response based on error type
-	status, code, message := classifyError(err)
+	lang := defaultRegistry.MatchLanguage(r.Header.Get("Accept-Language"))
+	status, code, message := classifyError(err, lang)
+	requestID := middleware.GetReqID(r.Context())
+	appErr, isAppErr := AsAppError(err)
 
 	// Log the error with full details
-	log.Error("request failed",
+	fields := []logger.Field{
 		logger.String("path", r.URL.Path),
 		logger.String("method", r.Method),
+		logger.String("request_id", requestID),
 		logger.Int("status", status),
 		logger.String("code", code),
 		logger.Error(err),
-	)
+	}
+	if isAppErr && len(appErr.Metadata) > 0 {
+		fields = append(fields, logger.Any("metadata", appErr.Metadata))
+	}
+	log.Error("request failed", fields...)
+
+	// Response shape and language depend on the request headers
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "Accept-Language")
+	w.Header().Set("Content-Language", lang.String())
+
+	// Legacy clients still get the old shape
+	if wantsLegacyError(r) {
+		w.Header().Set("Content-Type", ContentTypeJSON)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error: message,
+			Code:  code,
+		})
+		return
+	}
+
+	// Write problem+json response
+	problem := ProblemDetails{
+		Type:      problemType(code),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    message,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestID,
+	}
+	if status < http.StatusInternalServerError {
+		problem.Errors = localizeFieldErrors(FieldErrors(err), lang)
+	}
+	if isAppErr {
+		// Metadata of server errors may hold internal values
+		if status < http.StatusInternalServerError {
+			problem.Metadata = appErr.Metadata
+		}
+		problem.Retryable = appErr.Retryable
+	}
 
-	// Write JSON response
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", ContentTypeProblemJSON)
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(ErrorResponse{
-		Error: message,
-		Code:  code,
-	})
+	json.NewEncoder(w).Encode(problem)
 }
 
-// classifyError determines HTTP status code based on error type
-func classifyError(err error) (status int, code string, message string) {
+// classifyError determines HTTP status code based on error type.
+// Registered codes are localized in the negotiated language. An AppError
+// without its own code carries its client-safe detail; any other error
+// gets the localized category message. 5xx responses never carry details.
+func classifyError(err error, lang language.Tag) (status int, code string, message string) {
+	appErr, ok := AsAppError(err)
+	if !ok {
+		status, code = classifyCategory(err)
+		message, _ = defaultRegistry.Message(code, lang, nil)
+		return status, code, message
+	}
+
+	status, code = classifyCategory(appErr.Category)
+	if appErr.Retryable && status == http.StatusInternalServerError {
+		status, code = http.StatusServiceUnavailable, CodeServiceUnavailable
+	}
+	if appErr.Code != "" {
+		code = appErr.Code
+	}
+	if def, ok := defaultRegistry.Lookup(code); ok && def.Status != 0 {
+		status = def.Status
+	}
+
+	// A code of its own has a catalog message; without one, the detail
+	// says more than the category's generic message
+	if appErr.Code != "" {
+		if msg, ok := defaultRegistry.Message(code, lang, appErr.Metadata); ok {
+			return status, code, msg
+		}
+	}
+	if appErr.Detail != "" && status < http.StatusInternalServerError {
+		return status, code, appErr.Detail
+	}
+	message, _ = defaultRegistry.Message(code, lang, nil)
+	if message == "" {
+		_, categoryCode := classifyCategory(appErr.Category)
+		message, _ = defaultRegistry.Message(categoryCode, lang, nil)
+	}
+	return status, code, message
+}
+
+// classifyCategory maps the sentinel in err's chain to a status and code
+func classifyCategory(err error) (status int, code string) {
 	switch {
 	case Is(err, ErrValidation):
-		return http.StatusBadRequest, "VALIDATION_ERROR", err.Error()
+		return http.StatusBadRequest, CodeValidation
 	case Is(err, ErrNotFound):
-		return http.StatusNotFound, "NOT_FOUND", err.Error()
+		return http.StatusNotFound, CodeNotFound
 	case Is(err, ErrAlreadyExists):
-		return http.StatusConflict, "CONFLICT", err.Error()
+		return http.StatusConflict, CodeConflict
 	case Is(err, ErrInvalidInput):
-		return http.StatusBadRequest, "INVALID_INPUT", err.Error()
+		return http.StatusBadRequest, CodeInvalidInput
 	case Is(err, ErrBusinessRule):
-		return http.StatusBadRequest, "BUSINESS_RULE_VIOLATION", err.Error()
+		return http.StatusBadRequest, CodeBusinessRule
 	case Is(err, ErrUnauthorized):
-		return http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required"
+		return http.StatusUnauthorized, CodeUnauthorized
 	case Is(err, ErrForbidden):
-		return http.StatusForbidden, "FORBIDDEN", "Access denied"
+		return http.StatusForbidden, CodeForbidden
+	case Is(err, context.DeadlineExceeded):
+		// A handler that gave up when its request timeout expired
+		return http.StatusServiceUnavailable, CodeRequestTimeout
 	default:
 		// Unknown error - treat as internal server error
 		// Don't leak internal details to client
-		return http.StatusInternalServerError, "INTERNAL_ERROR", "An internal error occurred"
+		return http.StatusInternalServerError, CodeInternal
 	}
 }
+
+// localizeFieldErrors translates field messages that carry a registered code
+func localizeFieldErrors(fields []FieldError, lang language.Tag) []FieldError {
+	if len(fields) == 0 {
+		return nil
+	}
+	out := make([]FieldError, len(fields))
+	for i, f := range fields {
+		if f.Code != "" {
+			if msg, ok := defaultRegistry.Message(f.Code, lang, f.params); ok {
+				f.Message = msg
+			}
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// StatusOf returns the HTTP status used for errors in err's category
+func StatusOf(err error) int {
+	status, _ := classifyCategory(err)
+	return status
+}
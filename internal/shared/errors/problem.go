@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types used for error responses
+const (
+	ContentTypeProblemJSON = "application/problem+json"
+	ContentTypeJSON        = "application/json"
+)
+
+// problemTypeBase is the URI reference prefix for problem types
+const problemTypeBase = "/problems/"
+
+// ProblemDetails is an RFC 7807 problem response
+type ProblemDetails struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	Code      string                 `json:"code,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Retryable bool                   `json:"retryable,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Errors    []FieldError           `json:"errors,omitempty"`
+}
+
+// problemType builds the type URI reference for an error code,
+// e.g. VALIDATION_ERROR -> /problems/validation-error
+func problemType(code string) string {
+	if code == "" {
+		return "about:blank"
+	}
+	return problemTypeBase + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+// wantsLegacyError reports whether the client negotiated the legacy
+// {"error","code"} shape. Clients that accept application/json but not
+// application/problem+json get the legacy shape; everyone else, including
+// clients sending no Accept header, gets problem+json.
+func wantsLegacyError(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+
+	problemQ, jsonQ, anyQ := -1.0, -1.0, -1.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		switch mediaType {
+		case ContentTypeProblemJSON:
+			problemQ = max(problemQ, q)
+		case ContentTypeJSON:
+			jsonQ = max(jsonQ, q)
+		case "application/*", "*/*":
+			anyQ = max(anyQ, q)
+		}
+	}
+
+	if problemQ < 0 {
+		problemQ = anyQ
+	}
+	return jsonQ > 0 && jsonQ > problemQ
+}
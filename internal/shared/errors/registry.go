@@ -0,0 +1,223 @@
+package errors
+
+//go:generate go run ../../../cmd/errcodes -o ../../../docs/error-codes.md
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+)
+
+// CodeDefinition describes a registered error code
+type CodeDefinition struct {
+	// Code is the stable machine-readable identifier
+	Code string `json:"code"`
+	// Category is the sentinel the code belongs to (e.g. ErrBusinessRule)
+	Category error `json:"-"`
+	// Status overrides the HTTP status derived from Category, if non-zero
+	Status int `json:"status,omitempty"`
+	// Message is the default English client message. It may reference
+	// AppError metadata with {key} placeholders.
+	Message string `json:"message"`
+	// Description documents when the code is returned
+	Description string `json:"description,omitempty"`
+}
+
+// Registry holds error code definitions and their translations
+type Registry struct {
+	mu       sync.RWMutex
+	codes    map[string]CodeDefinition
+	catalogs map[language.Tag]map[string]string
+	tags     []language.Tag
+	matcher  language.Matcher
+}
+
+// NewRegistry creates an empty registry with English as the base language
+func NewRegistry() *Registry {
+	r := &Registry{
+		codes:    make(map[string]CodeDefinition),
+		catalogs: make(map[language.Tag]map[string]string),
+		tags:     []language.Tag{language.English},
+	}
+	r.matcher = language.NewMatcher(r.tags)
+	return r
+}
+
+// Register adds a code definition. It panics on duplicate or empty codes,
+// since registration happens at init time.
+func (r *Registry) Register(def CodeDefinition) {
+	if def.Code == "" {
+		panic("errors: registering empty error code")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.codes[def.Code]; exists {
+		panic(fmt.Sprintf("errors: error code %s already registered", def.Code))
+	}
+	r.codes[def.Code] = def
+}
+
+// AddCatalog adds translations for a language, merging with any existing ones
+func (r *Registry) AddCatalog(tag language.Tag, messages map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	catalog, ok := r.catalogs[tag]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		r.catalogs[tag] = catalog
+		r.tags = append(r.tags, tag)
+		r.matcher = language.NewMatcher(r.tags)
+	}
+	for code, msg := range messages {
+		catalog[code] = msg
+	}
+}
+
+// Lookup returns the definition of a code
+func (r *Registry) Lookup(code string) (CodeDefinition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.codes[code]
+	return def, ok
+}
+
+// Codes returns all registered definitions sorted by code
+func (r *Registry) Codes() []CodeDefinition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]CodeDefinition, 0, len(r.codes))
+	for _, def := range r.codes {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Code < defs[j].Code })
+	return defs
+}
+
+// Languages returns the languages with a catalog, base language first
+func (r *Registry) Languages() []language.Tag {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]language.Tag(nil), r.tags...)
+}
+
+// MatchLanguage picks the best supported language for an Accept-Language header
+func (r *Registry) MatchLanguage(acceptLanguage string) language.Tag {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(prefs) == 0 {
+		return language.English
+	}
+	_, index, confidence := r.matcher.Match(prefs...)
+	if confidence == language.No {
+		return language.English
+	}
+	return r.tags[index]
+}
+
+// Message returns the client message for a code in the given language,
+// with {key} placeholders filled from params. It reports false if the
+// code is not registered.
+func (r *Registry) Message(code string, tag language.Tag, params map[string]interface{}) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.codes[code]
+	if !ok {
+		return "", false
+	}
+
+	msg := def.Message
+	if translated, ok := r.catalogs[tag][code]; ok && translated != "" {
+		msg = translated
+	}
+	return interpolate(msg, params), true
+}
+
+// interpolate replaces {key} placeholders with values from params
+func interpolate(msg string, params map[string]interface{}) string {
+	if len(params) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for key, value := range params {
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// loadCatalogs registers every embedded locales/<lang>.json catalog
+func (r *Registry) loadCatalogs(fsys embed.FS, dir string) error {
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".json" {
+			continue
+		}
+
+		tag, err := language.Parse(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return fmt.Errorf("invalid catalog language %s: %w", name, err)
+		}
+
+		data, err := fsys.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("invalid catalog %s: %w", name, err)
+		}
+		r.AddCatalog(tag, messages)
+	}
+	return nil
+}
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// defaultRegistry is the process-wide registry used by WriteError
+var defaultRegistry = NewRegistry()
+
+func init() {
+	registerBuiltinCodes(defaultRegistry)
+	if err := defaultRegistry.loadCatalogs(localesFS, "locales"); err != nil {
+		panic(fmt.Sprintf("errors: loading catalogs: %v", err))
+	}
+}
+
+// RegisterCode adds a code definition to the default registry
+func RegisterCode(def CodeDefinition) {
+	defaultRegistry.Register(def)
+}
+
+// AddCatalog adds translations to the default registry
+func AddCatalog(tag language.Tag, messages map[string]string) {
+	defaultRegistry.AddCatalog(tag, messages)
+}
+
+// Codes returns all codes in the default registry
+func Codes() []CodeDefinition {
+	return defaultRegistry.Codes()
+}
+
+// DefaultRegistry returns the process-wide registry
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
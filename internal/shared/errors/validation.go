@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// FieldError describes a single field-level validation failure
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+
+	// params fill placeholders when the message is localized
+	params map[string]interface{}
+}
+
+// ValidationError collects field-level validation failures.
+// It matches ErrValidation via Is.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// NewValidationError creates a validation error from field violations
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{Fields: fields}
+}
+
+// Add records a field violation
+func (e *ValidationError) Add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// Addf records a field violation with a formatted message
+func (e *ValidationError) Addf(field, format string, args ...interface{}) {
+	e.Add(field, fmt.Sprintf(format, args...))
+}
+
+// AddCode records a field violation by registered error code.
+// The message is localized when the response is written.
+func (e *ValidationError) AddCode(field, code string, params map[string]interface{}) {
+	msg, ok := defaultRegistry.Message(code, language.English, params)
+	if !ok {
+		msg = code
+	}
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: msg, params: params})
+}
+
+// HasErrors reports whether any violations were recorded
+func (e *ValidationError) HasErrors() bool {
+	return len(e.Fields) > 0
+}
+
+// ErrOrNil returns the error if violations were recorded, nil otherwise
+func (e *ValidationError) ErrOrNil() error {
+	if !e.HasErrors() {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return ErrValidation.Error()
+	}
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation.Error(), strings.Join(parts, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// FieldErrors extracts field violations from err, if any
+func FieldErrors(err error) []FieldError {
+	var ve *ValidationError
+	if As(err, &ve) {
+		return ve.Fields
+	}
+	return nil
+}
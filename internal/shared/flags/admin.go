@@ -0,0 +1,102 @@
+package flags
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/request"
+)
+
+// flagRequest is the body of PUT /{key}
+type flagRequest struct {
+	Description    string   `json:"description"`
+	Enabled        bool     `json:"enabled"`
+	RolloutPercent int      `json:"rollout_percent"`
+	AllowUsers     []string `json:"allow_users"`
+	AllowTenants   []string `json:"allow_tenants"`
+}
+
+// AdminRoutes manages flags from the admin listener:
+//
+//	GET    /                      all flags
+//	GET    /{key}                 one flag
+//	PUT    /{key}                 create or replace a flag
+//	DELETE /{key}                 delete a flag
+//	GET    /{key}/evaluate        result for ?user_id= and ?tenant_id=
+func (s *Service) AdminRoutes(log logger.Logger) http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, s.List())
+	})
+
+	r.Get("/{key}", func(w http.ResponseWriter, r *http.Request) {
+		f, err := s.Get(r.Context(), chi.URLParam(r, "key"))
+		if err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+		writeJSON(w, http.StatusOK, f)
+	})
+
+	r.Put("/{key}", func(w http.ResponseWriter, r *http.Request) {
+		var req flagRequest
+		if err := request.DecodeJSON(r, &req); err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+		f, err := s.Save(r.Context(), Flag{
+			Key:            chi.URLParam(r, "key"),
+			Description:    req.Description,
+			Enabled:        req.Enabled,
+			RolloutPercent: req.RolloutPercent,
+			AllowUsers:     req.AllowUsers,
+			AllowTenants:   req.AllowTenants,
+		})
+		if err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+
+		log.Info("feature flag saved",
+			logger.String("flag", f.Key),
+			logger.Bool("enabled", f.Enabled),
+			logger.Int("rollout_percent", f.RolloutPercent),
+		)
+		writeJSON(w, http.StatusOK, f)
+	})
+
+	r.Delete("/{key}", func(w http.ResponseWriter, r *http.Request) {
+		key := chi.URLParam(r, "key")
+		if err := s.Delete(r.Context(), key); err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+		log.Info("feature flag deleted", logger.String("flag", key))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	r.Get("/{key}/evaluate", func(w http.ResponseWriter, r *http.Request) {
+		key := chi.URLParam(r, "key")
+		subject := Subject{
+			UserID:   r.URL.Query().Get("user_id"),
+			TenantID: r.URL.Query().Get("tenant_id"),
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"flag":    key,
+			"enabled": s.EnabledFor(key, subject),
+		})
+	})
+
+	return r
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
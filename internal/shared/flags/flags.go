@@ -0,0 +1,359 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/auth"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/tenant"
+)
+
+// notifyChannel tells every instance to reload flags
+const notifyChannel = "feature_flags_changed"
+
+// refreshInterval reloads flags even if a notification is lost
+const refreshInterval = time.Minute
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+var evaluations = metrics.NewCounterVec(
+	"feature_flag_evaluations_total",
+	"Feature flag evaluations by flag and result.",
+	"flag", "result",
+)
+
+// Flag is a feature flag. A flag is on for a request when it is enabled
+// and the user or tenant is allow-listed or falls inside the rollout
+// percentage. Disabling a flag is a kill switch: it turns the flag off
+// for everyone, allow-lists included.
+type Flag struct {
+	Key            string    `json:"key"`
+	Description    string    `json:"description"`
+	Enabled        bool      `json:"enabled"`
+	RolloutPercent int       `json:"rollout_percent"`
+	AllowUsers     []string  `json:"allow_users"`
+	AllowTenants   []string  `json:"allow_tenants"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Validate checks the flag fields
+func (f *Flag) Validate() error {
+	ve := apperrors.NewValidationError()
+	if !keyPattern.MatchString(f.Key) {
+		ve.Add("key", "must be lowercase letters, digits, '_', '.' or '-'")
+	}
+	if f.RolloutPercent < 0 || f.RolloutPercent > 100 {
+		ve.AddCode("rollout_percent", apperrors.CodeFieldRange, map[string]interface{}{"min": 0, "max": 100})
+	}
+	for _, id := range f.AllowTenants {
+		if !tenant.ValidID(id) {
+			ve.Addf("allow_tenants", "invalid tenant ID %q", id)
+		}
+	}
+	return ve.ErrOrNil()
+}
+
+// Subject is who a flag is evaluated for
+type Subject struct {
+	UserID   string
+	TenantID string
+}
+
+// SubjectFrom returns the authenticated user and tenant of ctx
+func SubjectFrom(ctx context.Context) Subject {
+	var s Subject
+	if claims, ok := auth.ClaimsFrom(ctx); ok {
+		s.UserID = claims.Subject
+	}
+	s.TenantID, _ = tenant.FromContext(ctx)
+	return s
+}
+
+// evaluate applies the flag's rules to a subject
+func (f *Flag) evaluate(s Subject) bool {
+	if !f.Enabled {
+		return false
+	}
+	if s.UserID != "" && slices.Contains(f.AllowUsers, s.UserID) {
+		return true
+	}
+	if s.TenantID != "" && slices.Contains(f.AllowTenants, s.TenantID) {
+		return true
+	}
+	if f.RolloutPercent >= 100 {
+		return true
+	}
+	if f.RolloutPercent <= 0 {
+		return false
+	}
+
+	// Bucket by user, or by tenant for requests without one, so a
+	// subject keeps its result as the percentage grows
+	unit := s.UserID
+	if unit == "" {
+		unit = s.TenantID
+	}
+	if unit == "" {
+		return false
+	}
+	return bucket(f.Key, unit) < f.RolloutPercent
+}
+
+// bucket maps a flag and subject to 0-99. Hashing the key in spreads
+// each flag's rollout over different users.
+func bucket(key, unit string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(unit))
+	return int(h.Sum32() % 100)
+}
+
+// Service evaluates flags from an in-memory snapshot that is reloaded
+// when any instance changes a flag
+type Service struct {
+	pool   *pgxpool.Pool
+	bus    *database.Bus
+	tm     *database.PostgresTransactionManager
+	logger logger.Logger
+
+	mu    sync.RWMutex
+	flags map[string]*Flag
+}
+
+// NewService creates a flag service. Flags are loaded when the component
+// starts; until then every flag is off.
+func NewService(pool *pgxpool.Pool, bus *database.Bus, log logger.Logger) *Service {
+	return &Service{
+		pool:   pool,
+		bus:    bus,
+		tm:     database.NewPostgresTransactionManager(pool),
+		logger: log,
+		flags:  make(map[string]*Flag),
+	}
+}
+
+// Enabled reports whether a flag is on for the user and tenant of ctx.
+// Unknown flags are off.
+func (s *Service) Enabled(ctx context.Context, key string) bool {
+	return s.EnabledFor(key, SubjectFrom(ctx))
+}
+
+// EnabledFor reports whether a flag is on for a subject
+func (s *Service) EnabledFor(key string, subject Subject) bool {
+	s.mu.RLock()
+	f, ok := s.flags[key]
+	s.mu.RUnlock()
+
+	on := ok && f.evaluate(subject)
+	evaluations.With(key, fmt.Sprint(on)).Inc()
+	return on
+}
+
+// List returns all flags from the snapshot, sorted by key
+func (s *Service) List() []Flag {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]Flag, 0, len(s.flags))
+	for _, f := range s.flags {
+		list = append(list, *f)
+	}
+	slices.SortFunc(list, func(a, b Flag) int {
+		if a.Key < b.Key {
+			return -1
+		}
+		if a.Key > b.Key {
+			return 1
+		}
+		return 0
+	})
+	return list
+}
+
+const flagColumns = "key, description, enabled, rollout_percent, allow_users, allow_tenants, created_at, updated_at"
+
+func scanFlag(row pgx.Row) (*Flag, error) {
+	f := &Flag{}
+	err := row.Scan(&f.Key, &f.Description, &f.Enabled, &f.RolloutPercent,
+		&f.AllowUsers, &f.AllowTenants, &f.CreatedAt, &f.UpdatedAt)
+	return f, err
+}
+
+// Get reads a flag from the database
+func (s *Service) Get(ctx context.Context, key string) (*Flag, error) {
+	f, err := scanFlag(s.pool.QueryRow(ctx, "SELECT "+flagColumns+" FROM feature_flags WHERE key = $1", key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Wrapf(apperrors.ErrNotFound, "feature flag %s", key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get feature flag: %v", apperrors.ErrDatabase, err)
+	}
+	return f, nil
+}
+
+// Save creates or replaces a flag and notifies every instance
+func (s *Service) Save(ctx context.Context, f Flag) (*Flag, error) {
+	if f.AllowUsers == nil {
+		f.AllowUsers = []string{}
+	}
+	if f.AllowTenants == nil {
+		f.AllowTenants = []string{}
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.tm.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		saved, err := scanFlag(database.GetQuerier(ctx, s.pool).QueryRow(ctx,
+			`INSERT INTO feature_flags (key, description, enabled, rollout_percent, allow_users, allow_tenants)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (key) DO UPDATE SET
+			     description = EXCLUDED.description,
+			     enabled = EXCLUDED.enabled,
+			     rollout_percent = EXCLUDED.rollout_percent,
+			     allow_users = EXCLUDED.allow_users,
+			     allow_tenants = EXCLUDED.allow_tenants,
+			     updated_at = now()
+			 RETURNING `+flagColumns,
+			f.Key, f.Description, f.Enabled, f.RolloutPercent, f.AllowUsers, f.AllowTenants,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to save feature flag: %v", apperrors.ErrDatabase, err)
+		}
+		return saved, s.notify(ctx, f.Key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	saved := result.(*Flag)
+	s.set(saved)
+	return saved, nil
+}
+
+// Delete removes a flag and notifies every instance
+func (s *Service) Delete(ctx context.Context, key string) error {
+	_, err := s.tm.WithTransaction(ctx, func(ctx context.Context) (interface{}, error) {
+		tag, err := database.GetQuerier(ctx, s.pool).Exec(ctx, "DELETE FROM feature_flags WHERE key = $1", key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to delete feature flag: %v", apperrors.ErrDatabase, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return nil, apperrors.Wrapf(apperrors.ErrNotFound, "feature flag %s", key)
+		}
+		return nil, s.notify(ctx, key)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.flags, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// notify publishes a change inside the transaction, so other instances
+// reload only after it commits
+func (s *Service) notify(ctx context.Context, key string) error {
+	if s.bus == nil {
+		return nil
+	}
+	return s.bus.Publish(ctx, notifyChannel, key)
+}
+
+func (s *Service) set(f *Flag) {
+	s.mu.Lock()
+	s.flags[f.Key] = f
+	s.mu.Unlock()
+}
+
+// Reload replaces the snapshot with the flags in the database
+func (s *Service) Reload(ctx context.Context) error {
+	rows, err := s.pool.Query(ctx, "SELECT "+flagColumns+" FROM feature_flags")
+	if err != nil {
+		return fmt.Errorf("%w: failed to load feature flags: %v", apperrors.ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	flags := make(map[string]*Flag)
+	for rows.Next() {
+		f, err := scanFlag(rows)
+		if err != nil {
+			return fmt.Errorf("%w: failed to scan feature flag: %v", apperrors.ErrDatabase, err)
+		}
+		flags[f.Key] = f
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: failed to load feature flags: %v", apperrors.ErrDatabase, err)
+	}
+
+	s.mu.Lock()
+	s.flags = flags
+	s.mu.Unlock()
+	return nil
+}
+
+// run reloads flags on change notifications, on resync and periodically.
+// sub is nil without a bus.
+func (s *Service) run(ctx context.Context, sub *database.Subscription) error {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	var changes <-chan database.Notification
+	if sub != nil {
+		defer sub.Close()
+		changes = sub.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-changes:
+			if !ok {
+				return nil
+			}
+		case <-ticker.C:
+		}
+
+		if err := s.Reload(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("failed to reload feature flags", logger.Error(err))
+		}
+	}
+}
+
+// Component returns a lifecycle component that loads flags before the
+// HTTP server starts and keeps them current
+func (s *Service) Component() lifecycle.Component {
+	var sub *database.Subscription
+	return lifecycle.Component{
+		Name:      "feature-flags",
+		DependsOn: []string{"database", "notifications"},
+		OnStart: func(ctx context.Context) error {
+			// Subscribe first so a change during the load isn't missed
+			if s.bus != nil {
+				sub = s.bus.Subscribe(notifyChannel, 16)
+			}
+			return s.Reload(ctx)
+		},
+		Run: func(ctx context.Context) error {
+			return s.run(ctx, sub)
+		},
+	}
+}
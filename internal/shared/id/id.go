@@ -0,0 +1,213 @@
+// Package id generates unique, time-sortable 64-bit IDs for orders,
+// trades and events without a database round-trip. An ID packs, from the
+// most significant bit:
+//
+//	1 bit   always 0, so IDs are positive BIGINTs
+//	41 bits milliseconds since Epoch (about 69 years)
+//	10 bits node ID from APP_NODE_ID
+//	12 bits sequence within the millisecond
+package id
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/clock"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/openapi"
+)
+
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+
+	// MaxNode is the largest node ID
+	MaxNode     = 1<<nodeBits - 1
+	maxSequence = 1<<sequenceBits - 1
+)
+
+// Epoch is the zero time of the timestamp bits
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ID is a generated identifier. The zero ID is never generated and
+// stands for "none".
+type ID int64
+
+// Generator hands out IDs for one node. It is safe for concurrent use.
+type Generator struct {
+	node  int64
+	clock clock.Clock
+
+	mu       sync.Mutex
+	lastMs   int64
+	sequence int64
+}
+
+// NewGenerator creates a generator for a node. Instances sharing a
+// database must use different nodes.
+func NewGenerator(node int) (*Generator, error) {
+	if node < 0 || node > MaxNode {
+		return nil, fmt.Errorf("node ID %d out of range 0-%d", node, MaxNode)
+	}
+	return &Generator{node: int64(node), clock: clock.Real{}}, nil
+}
+
+// WithClock timestamps IDs by c instead of the wall clock
+func (g *Generator) WithClock(c clock.Clock) *Generator {
+	g.clock = c
+	return g
+}
+
+// Next returns a new ID, greater than every ID this generator returned
+// before. When the 4096 IDs of a millisecond are used up, or the
+// clock steps back, it borrows from the next millisecond rather than
+// block or repeat.
+func (g *Generator) Next() ID {
+	ms := g.clock.Now().Sub(Epoch).Milliseconds()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if ms > g.lastMs {
+		g.lastMs, g.sequence = ms, 0
+	} else {
+		g.sequence++
+		if g.sequence > maxSequence {
+			g.lastMs, g.sequence = g.lastMs+1, 0
+		}
+	}
+	return ID(g.lastMs<<(nodeBits+sequenceBits) | g.node<<sequenceBits | g.sequence)
+}
+
+// Time returns when the ID was generated, to the millisecond
+func (id ID) Time() time.Time {
+	return Epoch.Add(time.Duration(int64(id)>>(nodeBits+sequenceBits)) * time.Millisecond)
+}
+
+// Node returns the node that generated the ID
+func (id ID) Node() int {
+	return int(int64(id) >> sequenceBits & MaxNode)
+}
+
+// IsZero reports whether id is the zero ID
+func (id ID) IsZero() bool {
+	return id == 0
+}
+
+// alphabet is Crockford's base32, which has no I, L, O or U
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// stringLen is the fixed length of the string form; fixed width keeps
+// strings sorting like the IDs
+const stringLen = 13
+
+var decoding = func() (table [256]int8) {
+	for i := range table {
+		table[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		table[alphabet[i]] = int8(i)
+		table[strings.ToLower(alphabet[i : i+1])[0]] = int8(i)
+	}
+	return table
+}()
+
+// String returns the 13-character base32 form used by the API, e.g.
+// "0F8HZ6M2K4G00"
+func (id ID) String() string {
+	var buf [stringLen]byte
+	n := uint64(id)
+	for i := stringLen - 1; i >= 0; i-- {
+		buf[i] = alphabet[n&31]
+		n >>= 5
+	}
+	return string(buf[:])
+}
+
+// Parse reads the string form, case-insensitively
+func Parse(s string) (ID, error) {
+	if len(s) != stringLen {
+		return 0, fmt.Errorf("invalid ID %q: must be %d characters", s, stringLen)
+	}
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		v := decoding[s[i]]
+		if v < 0 {
+			return 0, fmt.Errorf("invalid ID %q: unexpected character %q", s, s[i])
+		}
+		n = n<<5 | uint64(v)
+	}
+	// 13 characters hold 65 bits; the top one and the sign bit must be 0
+	if decoding[s[0]] > 7 {
+		return 0, fmt.Errorf("invalid ID %q: out of range", s)
+	}
+	return ID(n), nil
+}
+
+// MarshalJSON writes the string form; JavaScript numbers can't hold
+// 64-bit integers
+func (id ID) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + id.String() + `"`), nil
+}
+
+// UnmarshalJSON reads the string form
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: "number", Type: reflect.TypeOf(ID(0))}
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return &json.UnmarshalTypeError{Value: "string " + strconv.Quote(s), Type: reflect.TypeOf(ID(0))}
+	}
+	*id = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler, e.g. for URL parameters
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (id *ID) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
+
+// OpenAPISchema implements openapi.SchemaProvider
+func (ID) OpenAPISchema() *openapi.Schema {
+	return &openapi.Schema{
+		Type:    "string",
+		Pattern: "^[0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{13}$",
+		Example: "0A8SBDPFQZXRF",
+	}
+}
+
+// ScanInt64 implements pgtype.Int64Scanner for BIGINT columns. Scan
+// nullable columns into *ID.
+func (id *ID) ScanInt64(v pgtype.Int8) error {
+	if !v.Valid {
+		return fmt.Errorf("cannot scan NULL into id.ID")
+	}
+	*id = ID(v.Int64)
+	return nil
+}
+
+// Int64Value implements pgtype.Int64Valuer
+func (id ID) Int64Value() (pgtype.Int8, error) {
+	return pgtype.Int8{Int64: int64(id), Valid: true}, nil
+}
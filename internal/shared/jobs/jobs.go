@@ -0,0 +1,235 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// DefaultQueue is used when no queue is given
+const DefaultQueue = "default"
+
+// notifyChannel wakes workers when jobs are enqueued
+const notifyChannel = "jobs_enqueued"
+
+// Job statuses
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead"
+)
+
+// Job is a unit of background work as seen by a handler
+type Job struct {
+	ID          int64
+	Queue       string
+	Kind        string
+	Payload     json.RawMessage
+	Priority    int
+	Attempt     int // 1 on the first run
+	MaxAttempts int
+	RunAt       time.Time
+	CreatedAt   time.Time
+}
+
+// Handler processes a job. Returning an error retries the job with
+// backoff until MaxAttempts, then moves it to the dead status.
+type Handler func(ctx context.Context, job *Job) error
+
+// Permanent marks an error as not worth retrying; the job goes straight
+// to dead
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Queue enqueues jobs and runs workers for the configured queues
+type Queue struct {
+	pool   *pgxpool.Pool
+	bus    *database.Bus
+	config *config.JobsConfig
+	logger logger.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewQueue creates a job queue. bus may be nil, in which case workers
+// only poll.
+func NewQueue(pool *pgxpool.Pool, bus *database.Bus, cfg *config.JobsConfig, log logger.Logger) *Queue {
+	return &Queue{
+		pool:     pool,
+		bus:      bus,
+		config:   cfg,
+		logger:   log,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle registers the handler for a job kind. It panics on duplicate
+// kinds, like http.ServeMux.
+func (q *Queue) Handle(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, exists := q.handlers[kind]; exists {
+		panic(fmt.Sprintf("jobs: handler for %q already registered", kind))
+	}
+	q.handlers[kind] = h
+}
+
+// Register registers a typed handler; the payload is decoded from JSON
+// before fn is called. A payload that can't be decoded is permanent.
+func Register[T any](q *Queue, kind string, fn func(ctx context.Context, job *Job, payload T) error) {
+	q.Handle(kind, func(ctx context.Context, job *Job) error {
+		var payload T
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return Permanent(fmt.Errorf("invalid payload: %w", err))
+		}
+		return fn(ctx, job, payload)
+	})
+}
+
+func (q *Queue) handler(kind string) (Handler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	h, ok := q.handlers[kind]
+	return h, ok
+}
+
+// enqueueOptions are set with Option functions
+type enqueueOptions struct {
+	queue       string
+	priority    int
+	runAt       time.Time
+	maxAttempts int
+	uniqueKey   string
+}
+
+// Option configures an enqueued job
+type Option func(*enqueueOptions)
+
+// OnQueue puts the job on a named queue
+func OnQueue(name string) Option {
+	return func(o *enqueueOptions) { o.queue = name }
+}
+
+// WithPriority sets the priority; higher runs first
+func WithPriority(priority int) Option {
+	return func(o *enqueueOptions) { o.priority = priority }
+}
+
+// RunAt schedules the job for a time
+func RunAt(t time.Time) Option {
+	return func(o *enqueueOptions) { o.runAt = t }
+}
+
+// RunIn schedules the job after a delay
+func RunIn(d time.Duration) Option {
+	return func(o *enqueueOptions) { o.runAt = time.Now().Add(d) }
+}
+
+// MaxAttempts limits how often the job runs before it is dead
+func MaxAttempts(n int) Option {
+	return func(o *enqueueOptions) { o.maxAttempts = n }
+}
+
+// Unique skips the enqueue if a pending or running job of the same kind
+// has the same key; the existing job's ID is returned
+func Unique(key string) Option {
+	return func(o *enqueueOptions) { o.uniqueKey = key }
+}
+
+// Enqueue adds a job. Inside WithTransaction the job is written in the
+// transaction, so it only becomes visible, and only runs, if the
+// transaction commits.
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload interface{}, opts ...Option) (int64, error) {
+	o := enqueueOptions{queue: DefaultQueue, maxAttempts: 10}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxAttempts < 1 {
+		return 0, apperrors.Wrap(apperrors.ErrInvalidInput, "max attempts must be at least 1")
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	var runAt *time.Time
+	if !o.runAt.IsZero() {
+		runAt = &o.runAt
+	}
+	var uniqueKey *string
+	if o.uniqueKey != "" {
+		uniqueKey = &o.uniqueKey
+	}
+
+	db := database.GetQuerier(ctx, q.pool)
+	var id int64
+	err = db.QueryRow(ctx,
+		`INSERT INTO jobs (queue, kind, payload, priority, run_at, max_attempts, unique_key)
+		 VALUES ($1, $2, $3, $4, COALESCE($5, now()), $6, $7)
+		 ON CONFLICT (kind, unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'running')
+		 DO NOTHING
+		 RETURNING id`,
+		o.queue, kind, data, o.priority, runAt, o.maxAttempts, uniqueKey,
+	).Scan(&id)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Duplicate unique job
+		err = db.QueryRow(ctx,
+			`SELECT id FROM jobs
+			 WHERE kind = $1 AND unique_key = $2 AND status IN ('pending', 'running')`,
+			kind, o.uniqueKey,
+		).Scan(&id)
+		if err != nil {
+			return 0, fmt.Errorf("%w: failed to find existing job: %v", apperrors.ErrDatabase, err)
+		}
+		return id, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w: failed to enqueue job: %v", apperrors.ErrDatabase, err)
+	}
+
+	// Wake workers; inside a transaction this is delivered on commit
+	if q.bus != nil {
+		if err := q.bus.Publish(ctx, notifyChannel, o.queue); err != nil {
+			q.logger.Warn("failed to notify job workers", logger.Error(err))
+		}
+	}
+	return id, nil
+}
+
+// RetryDead moves a dead job back to pending with a fresh attempt budget
+func (q *Queue) RetryDead(ctx context.Context, id int64) error {
+	tag, err := database.GetQuerier(ctx, q.pool).Exec(ctx,
+		`UPDATE jobs
+		 SET status = 'pending', attempts = 0, run_at = now(), finished_at = NULL, updated_at = now()
+		 WHERE id = $1 AND status = 'dead'`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: failed to retry job: %v", apperrors.ErrDatabase, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return apperrors.Wrapf(apperrors.ErrNotFound, "dead job %d", id)
+	}
+	return nil
+}
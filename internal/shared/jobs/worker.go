@@ -0,0 +1,352 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+)
+
+var (
+	jobsProcessed = metrics.NewCounterVec(
+		"jobs_processed_total",
+		"Jobs run by queue, kind and result (succeeded, retried, dead).",
+		"queue", "kind", "result",
+	)
+	jobDuration = metrics.NewHistogramVec(
+		"job_duration_seconds",
+		"Job execution time by queue and kind.",
+		nil,
+		"queue", "kind",
+	)
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Hour
+)
+
+// fetchQuery claims the next due job of a queue. SKIP LOCKED lets many
+// workers fetch concurrently without blocking on each other.
+const fetchQuery = `
+	UPDATE jobs
+	SET status = 'running', attempts = attempts + 1,
+	    locked_by = $2, locked_at = now(), updated_at = now()
+	WHERE id = (
+	    SELECT id FROM jobs
+	    WHERE queue = $1 AND status = 'pending' AND run_at <= now()
+	    ORDER BY priority DESC, run_at, id
+	    FOR UPDATE SKIP LOCKED
+	    LIMIT 1
+	)
+	RETURNING id, queue, kind, payload, priority, attempts, max_attempts, run_at, created_at`
+
+// backoff returns the delay before retry number attempt: exponential
+// with jitter, capped at maxBackoff
+func backoff(attempt int) time.Duration {
+	d := minBackoff << min(attempt-1, 20)
+	if d > maxBackoff || d <= 0 {
+		d = maxBackoff
+	}
+	// +/- 20% so retries of a failed batch spread out
+	jitter := time.Duration(rand.Int64N(int64(d)/5*2+1)) - d/5
+	return d + jitter
+}
+
+// workerID identifies this process in locked_by
+func workerID() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// Run starts the configured workers and blocks until ctx is cancelled.
+// Workers stop fetching when draining is closed and finish their
+// current job; cancelling ctx also cancels running jobs.
+func (q *Queue) Run(ctx context.Context, draining <-chan struct{}) error {
+	queues, err := q.config.QueueConcurrency()
+	if err != nil {
+		return err
+	}
+
+	// One wake channel per queue; NOTIFY payloads are queue names
+	wake := make(map[string]chan struct{}, len(queues))
+	for name := range queues {
+		wake[name] = make(chan struct{}, 1)
+	}
+
+	var wg sync.WaitGroup
+	if q.bus != nil {
+		sub := q.bus.Subscribe(notifyChannel, 64)
+		defer sub.Close()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case n, ok := <-sub.C:
+					if !ok {
+						return
+					}
+					q.wakeWorkers(n, wake)
+				}
+			}
+		}()
+	}
+
+	id := workerID()
+	for name, concurrency := range queues {
+		for i := 0; i < concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				q.work(ctx, draining, name, fmt.Sprintf("%s/%s-%d", id, name, i), wake[name])
+			}()
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.maintain(ctx, draining)
+	}()
+
+	q.logger.Info("job workers started", logger.Any("queues", queues))
+	wg.Wait()
+	return nil
+}
+
+// wakeWorkers signals the queue named in a notification; a resync wakes
+// every queue
+func (q *Queue) wakeWorkers(n database.Notification, wake map[string]chan struct{}) {
+	if n.Resync {
+		for _, ch := range wake {
+			signal(ch)
+		}
+		return
+	}
+	name, err := database.Decode[string](n)
+	if err != nil {
+		return
+	}
+	if ch, ok := wake[name]; ok {
+		signal(ch)
+	}
+}
+
+// signal does a non-blocking send; one pending wake-up is enough
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// work fetches and runs jobs until draining or ctx is done. After an
+// empty fetch it sleeps until woken or the poll interval passes.
+func (q *Queue) work(ctx context.Context, draining <-chan struct{}, queue, workerID string, wake chan struct{}) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-draining:
+			return
+		default:
+		}
+
+		job, err := q.fetch(ctx, queue, workerID)
+		if err != nil && ctx.Err() == nil {
+			q.logger.Error("failed to fetch job", logger.String("queue", queue), logger.Error(err))
+		}
+		if job != nil {
+			q.execute(ctx, job, workerID)
+			// Likely more work; fetch again right away and pass the
+			// wake-up on to an idle worker
+			signal(wake)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-draining:
+			return
+		case <-wake:
+		case <-time.After(q.config.PollInterval):
+		}
+	}
+}
+
+// fetch claims the next due job, or returns nil if there is none
+func (q *Queue) fetch(ctx context.Context, queue, workerID string) (*Job, error) {
+	job := &Job{}
+	err := q.pool.QueryRow(ctx, fetchQuery, queue, workerID).Scan(
+		&job.ID, &job.Queue, &job.Kind, &job.Payload, &job.Priority,
+		&job.Attempt, &job.MaxAttempts, &job.RunAt, &job.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// execute runs the handler and records the outcome. The result is only
+// recorded while workerID still holds the job; once the rescuer has
+// handed it to another worker, that worker's run decides.
+func (q *Queue) execute(ctx context.Context, job *Job, workerID string) {
+	log := q.logger.With(
+		logger.Int64("job_id", job.ID),
+		logger.String("queue", job.Queue),
+		logger.String("kind", job.Kind),
+		logger.Int("attempt", job.Attempt),
+	)
+
+	start := time.Now()
+	jobErr := q.runHandler(ctx, job)
+	elapsed := time.Since(start)
+	jobDuration.With(job.Queue, job.Kind).ObserveDuration(elapsed)
+
+	// Record the result even if the worker is stopping
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	var tag pgconn.CommandTag
+	var err error
+	var permanent *permanentError
+	switch {
+	case jobErr == nil:
+		tag, err = q.pool.Exec(finishCtx,
+			`UPDATE jobs SET status = 'succeeded', last_error = NULL, locked_by = NULL,
+			        finished_at = now(), updated_at = now()
+			 WHERE id = $1 AND status = 'running' AND locked_by = $2`, job.ID, workerID)
+		jobsProcessed.With(job.Queue, job.Kind, "succeeded").Inc()
+		log.Debug("job succeeded", logger.Duration("duration", elapsed))
+
+	case job.Attempt >= job.MaxAttempts || errors.As(jobErr, &permanent):
+		tag, err = q.pool.Exec(finishCtx,
+			`UPDATE jobs SET status = 'dead', last_error = $2, locked_by = NULL,
+			        finished_at = now(), updated_at = now()
+			 WHERE id = $1 AND status = 'running' AND locked_by = $3`, job.ID, jobErr.Error(), workerID)
+		jobsProcessed.With(job.Queue, job.Kind, "dead").Inc()
+		log.Error("job failed permanently, moved to dead", logger.Error(jobErr), logger.Duration("duration", elapsed))
+
+	default:
+		delay := backoff(job.Attempt)
+		tag, err = q.pool.Exec(finishCtx,
+			`UPDATE jobs SET status = 'pending', last_error = $2, locked_by = NULL,
+			        run_at = now() + $3::interval, updated_at = now()
+			 WHERE id = $1 AND status = 'running' AND locked_by = $4`, job.ID, jobErr.Error(), delay, workerID)
+		jobsProcessed.With(job.Queue, job.Kind, "retried").Inc()
+		log.Warn("job failed, will retry",
+			logger.Error(jobErr),
+			logger.Duration("duration", elapsed),
+			logger.Duration("retry_in", delay),
+		)
+	}
+
+	if err != nil {
+		// The rescuer will pick the job up again
+		log.Error("failed to record job result", logger.Error(err))
+	} else if tag.RowsAffected() == 0 {
+		log.Warn("job was rescued while running, result discarded")
+	}
+}
+
+// runHandler calls the job's handler with the job timeout, turning a
+// panic into an error
+func (q *Queue) runHandler(ctx context.Context, job *Job) (err error) {
+	h, ok := q.handler(job.Kind)
+	if !ok {
+		// Retried rather than dead: a newer deployment may handle it
+		return fmt.Errorf("no handler registered for job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v\n%s", p, debug.Stack())
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, q.config.Timeout)
+	defer cancel()
+	return h(ctx, job)
+}
+
+// maintain periodically rescues jobs abandoned by crashed workers and
+// deletes finished jobs past retention
+func (q *Queue) maintain(ctx context.Context, draining <-chan struct{}) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-draining:
+			return
+		case <-ticker.C:
+		}
+
+		// A job that has used its attempts is not run again: it may be
+		// the one crashing its workers
+		tag, err := q.pool.Exec(ctx,
+			`UPDATE jobs SET
+			     status      = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+			     finished_at = CASE WHEN attempts >= max_attempts THEN now() END,
+			     locked_by   = NULL,
+			     last_error  = 'rescued after worker timeout',
+			     updated_at  = now()
+			 WHERE status = 'running' AND locked_at < now() - $1::interval`,
+			q.config.RescueAfter,
+		)
+		if err != nil {
+			q.logger.Error("failed to rescue stuck jobs", logger.Error(err))
+		} else if n := tag.RowsAffected(); n > 0 {
+			q.logger.Warn("rescued stuck jobs", logger.Int64("count", n))
+		}
+
+		if _, err := q.pool.Exec(ctx,
+			`DELETE FROM jobs WHERE finished_at < now() - $1::interval`,
+			q.config.Retention,
+		); err != nil {
+			q.logger.Error("failed to delete old jobs", logger.Error(err))
+		}
+	}
+}
+
+// Component returns a lifecycle component running the workers. Draining
+// stops fetching new jobs while in-flight jobs finish; stopping cancels
+// them.
+func (q *Queue) Component() lifecycle.Component {
+	draining := make(chan struct{})
+	var once sync.Once
+
+	return lifecycle.Component{
+		Name:      "jobs",
+		DependsOn: []string{"database", "notifications"},
+		Run: func(ctx context.Context) error {
+			return q.Run(ctx, draining)
+		},
+		OnDrain: func(ctx context.Context) error {
+			once.Do(func() { close(draining) })
+			return nil
+		},
+	}
+}
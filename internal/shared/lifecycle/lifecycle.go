@@ -0,0 +1,343 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// Component is a unit of the application with start and stop hooks.
+// All hooks are optional.
+type Component struct {
+	// Name identifies the component in logs and dependency lists
+	Name string
+
+	// DependsOn lists components that must start before this one
+	// and stop after it
+	DependsOn []string
+
+	// OnStart prepares the component (open connections, bind listeners).
+	// It must not block; long-running work belongs in Run.
+	OnStart func(ctx context.Context) error
+
+	// Run is the component's main loop, started after OnStart succeeds.
+	// Its context is cancelled when the component stops. Returning an
+	// error before shutdown triggers an application-wide shutdown.
+	Run func(ctx context.Context) error
+
+	// OnDrain is called on every component, in reverse start order,
+	// before any OnStop. Use it to stop accepting new work while
+	// dependencies are still available.
+	OnDrain func(ctx context.Context) error
+
+	// OnStop releases the component's resources
+	OnStop func(ctx context.Context) error
+
+	// StartTimeout and StopTimeout override the manager defaults
+	StartTimeout time.Duration
+	StopTimeout  time.Duration
+}
+
+// Manager coordinates ordered startup and shutdown of components
+type Manager struct {
+	config *config.LifecycleConfig
+	logger logger.Logger
+
+	mu         sync.Mutex
+	components []*component
+	started    []*component
+	failures   chan error
+	stopOnce   sync.Once
+	stopErr    error
+}
+
+// component tracks the runtime state of a registered Component
+type component struct {
+	Component
+	cancelRun context.CancelFunc
+	runDone   chan struct{}
+}
+
+// NewManager creates a new lifecycle manager
+func NewManager(cfg *config.LifecycleConfig, log logger.Logger) *Manager {
+	return &Manager{
+		config:   cfg,
+		logger:   log,
+		failures: make(chan error, 1),
+	}
+}
+
+// Register adds a component. Components must be registered before Start.
+func (m *Manager) Register(c Component) error {
+	if c.Name == "" {
+		return errors.New("component name is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.components {
+		if existing.Name == c.Name {
+			return fmt.Errorf("component %s already registered", c.Name)
+		}
+	}
+	m.components = append(m.components, &component{Component: c})
+	return nil
+}
+
+// MustRegister is like Register but panics on error
+func (m *Manager) MustRegister(c Component) {
+	if err := m.Register(c); err != nil {
+		panic(err)
+	}
+}
+
+// Start starts all components in dependency order. If a component fails
+// to start, the already started ones are stopped in reverse order.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	ordered, err := sortComponents(m.components)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range ordered {
+		if err := m.startComponent(ctx, c); err != nil {
+			m.logger.Error("component failed to start",
+				logger.String("component", c.Name),
+				logger.Error(err),
+			)
+			if stopErr := m.Stop(context.Background()); stopErr != nil {
+				return errors.Join(fmt.Errorf("failed to start %s: %w", c.Name, err), stopErr)
+			}
+			return fmt.Errorf("failed to start %s: %w", c.Name, err)
+		}
+	}
+
+	m.logger.Info("all components started", logger.Int("count", len(ordered)))
+	return nil
+}
+
+// startComponent runs OnStart with its timeout and launches Run
+func (m *Manager) startComponent(ctx context.Context, c *component) error {
+	begin := time.Now()
+
+	if c.OnStart != nil {
+		startCtx, cancel := context.WithTimeout(ctx, m.timeout(c.StartTimeout, m.config.StartTimeout))
+		err := c.OnStart(startCtx)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.started = append(m.started, c)
+	m.mu.Unlock()
+
+	if c.Run != nil {
+		runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c.cancelRun = cancel
+		c.runDone = make(chan struct{})
+		go func() {
+			defer close(c.runDone)
+			if err := c.Run(runCtx); err != nil && runCtx.Err() == nil {
+				m.fail(fmt.Errorf("component %s stopped unexpectedly: %w", c.Name, err))
+			}
+		}()
+	}
+
+	m.logger.Info("component started",
+		logger.String("component", c.Name),
+		logger.Duration("duration", time.Since(begin)),
+	)
+	return nil
+}
+
+// fail records the first runtime failure
+func (m *Manager) fail(err error) {
+	m.logger.Error("component failed", logger.Error(err))
+	select {
+	case m.failures <- err:
+	default:
+	}
+}
+
+// Failures returns a channel that receives the first runtime failure
+func (m *Manager) Failures() <-chan error {
+	return m.failures
+}
+
+// Stop drains and stops all started components in reverse start order.
+// The whole sequence is bounded by LifecycleConfig.ShutdownTimeout.
+// Stop is idempotent.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.stopOnce.Do(func() {
+		m.stopErr = m.stop(ctx)
+	})
+	return m.stopErr
+}
+
+func (m *Manager) stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.ShutdownTimeout)
+	defer cancel()
+
+	m.mu.Lock()
+	started := append([]*component(nil), m.started...)
+	m.mu.Unlock()
+
+	var errs []error
+
+	// 1. Drain: stop accepting new work everywhere first
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		if c.OnDrain == nil {
+			continue
+		}
+		if err := m.callWithTimeout(ctx, c.StopTimeout, c.OnDrain); err != nil {
+			m.logger.Error("component drain failed", logger.String("component", c.Name), logger.Error(err))
+			errs = append(errs, fmt.Errorf("drain %s: %w", c.Name, err))
+		}
+	}
+
+	// 2. Stop in reverse start order
+	for i := len(started) - 1; i >= 0; i-- {
+		c := started[i]
+		begin := time.Now()
+
+		if c.cancelRun != nil {
+			c.cancelRun()
+		}
+		if c.OnStop != nil {
+			if err := m.callWithTimeout(ctx, c.StopTimeout, c.OnStop); err != nil {
+				m.logger.Error("component stop failed", logger.String("component", c.Name), logger.Error(err))
+				errs = append(errs, fmt.Errorf("stop %s: %w", c.Name, err))
+			}
+		}
+		if c.runDone != nil {
+			if err := m.waitRun(ctx, c); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		m.logger.Info("component stopped",
+			logger.String("component", c.Name),
+			logger.Duration("duration", time.Since(begin)),
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+// waitRun waits for a component's Run loop to return
+func (m *Manager) waitRun(ctx context.Context, c *component) error {
+	timer := time.NewTimer(m.timeout(c.StopTimeout, m.config.StopTimeout))
+	defer timer.Stop()
+
+	select {
+	case <-c.runDone:
+		return nil
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	m.logger.Warn("component did not exit in time", logger.String("component", c.Name))
+	return fmt.Errorf("component %s did not exit in time", c.Name)
+}
+
+// callWithTimeout calls fn bounded by the component's stop timeout
+func (m *Manager) callWithTimeout(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout(timeout, m.config.StopTimeout))
+	defer cancel()
+	return fn(ctx)
+}
+
+// Run starts all components, blocks until a shutdown signal, context
+// cancellation or component failure, then stops everything in order.
+func (m *Manager) Run(ctx context.Context) error {
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var runErr error
+	select {
+	case sig := <-signals:
+		m.logger.Info("shutdown signal received", logger.String("signal", sig.String()))
+	case <-ctx.Done():
+		m.logger.Info("shutdown requested", logger.Error(ctx.Err()))
+	case runErr = <-m.failures:
+		m.logger.Error("shutting down after component failure", logger.Error(runErr))
+	}
+
+	stopErr := m.Stop(context.Background())
+	if stopErr == nil && runErr == nil {
+		m.logger.Info("application stopped gracefully")
+	}
+	return errors.Join(runErr, stopErr)
+}
+
+// timeout returns override if set, otherwise the default
+func (m *Manager) timeout(override, fallback time.Duration) time.Duration {
+	if override > 0 {
+		return override
+	}
+	return fallback
+}
+
+// sortComponents orders components so dependencies come first.
+// Registration order is kept among independent components.
+func sortComponents(components []*component) ([]*component, error) {
+	byName := make(map[string]*component, len(components))
+	for _, c := range components {
+		byName[c.Name] = c
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(components))
+	ordered := make([]*component, 0, len(components))
+
+	var visit func(c *component, path []string) error
+	visit = func(c *component, path []string) error {
+		switch state[c.Name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v", append(path, c.Name))
+		}
+		state[c.Name] = visiting
+		for _, dep := range c.DependsOn {
+			d, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("component %s depends on unknown component %s", c.Name, dep)
+			}
+			if err := visit(d, append(path, c.Name)); err != nil {
+				return err
+			}
+		}
+		state[c.Name] = visited
+		ordered = append(ordered, c)
+		return nil
+	}
+
+	for _, c := range components {
+		if err := visit(c, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
@@ -37,25 +38,22 @@ var (
 	Any      = zap.Any
 )
 
+// LevelController changes the log level at runtime
+type LevelController interface {
+	Level() string
+	SetLevel(level string) error
+}
+
 // ZapLogger wraps zap.Logger to implement Logger interface
 type ZapLogger struct {
 	logger *zap.Logger
+	level  zap.AtomicLevel
 }
 
 // NewLogger creates a new structured logger
 func NewLogger(cfg *config.AppConfig) (Logger, error) {
-	// Determine log level
-	level := zapcore.InfoLevel
-	switch cfg.LogLevel {
-	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
-	case "warn":
-		level = zapcore.WarnLevel
-	case "error":
-		level = zapcore.ErrorLevel
-	}
+	// Determine log level; it can be changed at runtime via SetLevel
+	level := zap.NewAtomicLevelAt(parseLevel(cfg.LogLevel))
 
 	// Create encoder config
 	encoderConfig := zapcore.EncoderConfig{
@@ -91,7 +89,21 @@ func NewLogger(cfg *config.AppConfig) (Logger, error) {
 	// Create logger
 	zapLogger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 
-	return &ZapLogger{logger: zapLogger}, nil
+	return &ZapLogger{logger: zapLogger, level: level}, nil
+}
+
+// parseLevel maps a config level name to a zap level, defaulting to info
+func parseLevel(name string) zapcore.Level {
+	switch name {
+	case "debug":
+		return zapcore.DebugLevel
+	case "warn":
+		return zapcore.WarnLevel
+	case "error":
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
 }
 
 func (l *ZapLogger) Debug(msg string, fields ...Field) {
@@ -115,7 +127,23 @@ func (l *ZapLogger) Fatal(msg string, fields ...Field) {
 }
 
 func (l *ZapLogger) With(fields ...Field) Logger {
-	return &ZapLogger{logger: l.logger.With(fields...)}
+	return &ZapLogger{logger: l.logger.With(fields...), level: l.level}
+}
+
+// Level returns the current log level
+func (l *ZapLogger) Level() string {
+	return l.level.Level().String()
+}
+
+// SetLevel changes the log level of this logger and all loggers derived from it
+func (l *ZapLogger) SetLevel(level string) error {
+	switch level {
+	case "debug", "info", "warn", "error":
+		l.level.SetLevel(parseLevel(level))
+		return nil
+	default:
+		return fmt.Errorf("unknown log level %q", level)
+	}
 }
 
 func (l *ZapLogger) WithContext(ctx context.Context) Logger {
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var (
+	httpRequestsTotal = NewCounterVec(
+		"http_requests_total",
+		"Total HTTP requests by route, method and status.",
+		"route", "method", "status",
+	)
+	httpRequestDuration = NewHistogramVec(
+		"http_request_duration_seconds",
+		"HTTP request latency by route and method.",
+		nil,
+		"route", "method",
+	)
+	httpRequestsInFlight = NewGaugeVec(
+		"http_requests_in_flight",
+		"HTTP requests currently being served.",
+	)
+)
+
+func init() {
+	startTime := time.Now()
+
+	NewGaugeFunc("process_uptime_seconds", "Seconds since the process started.", func() float64 {
+		return time.Since(startTime).Seconds()
+	})
+	NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	NewGaugeFunc("go_memstats_heap_alloc_bytes", "Bytes of allocated heap objects.", func() float64 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return float64(m.HeapAlloc)
+	})
+}
+
+// Handler serves DefaultRegistry in Prometheus text format
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		DefaultRegistry.Write(&buf)
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	})
+}
+
+// Middleware records request count, latency and in-flight requests.
+// Requests are labelled by chi route pattern and standard method to keep
+// cardinality bounded.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight := httpRequestsInFlight.With()
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		method := methodLabel(r.Method)
+		httpRequestsTotal.With(route, method, strconv.Itoa(status)).Inc()
+		httpRequestDuration.With(route, method).ObserveDuration(time.Since(start))
+	})
+}
+
+// methodLabel returns method if it is a standard HTTP method and OTHER
+// otherwise; clients may send any token as the method
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
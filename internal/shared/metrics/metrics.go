@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Collector writes its samples in Prometheus text exposition format
+type Collector interface {
+	// Name returns the metric family name
+	Name() string
+	// Write writes HELP, TYPE and sample lines
+	Write(w io.Writer)
+}
+
+// Registry holds collectors exposed by the metrics endpoint
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]Collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]Collector),
+	}
+}
+
+// Register adds a collector. It panics if the name is taken, since
+// metrics are registered at init time.
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.collectors[c.Name()]; exists {
+		panic(fmt.Sprintf("metrics: collector %s already registered", c.Name()))
+	}
+	r.collectors[c.Name()] = c
+}
+
+// Write writes all collectors sorted by name
+func (r *Registry) Write(w io.Writer) {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].Name() < collectors[j].Name() })
+	for _, c := range collectors {
+		c.Write(w)
+	}
+}
+
+// DefaultRegistry is the process-wide registry
+var DefaultRegistry = NewRegistry()
+
+// metricVec is the label bookkeeping shared by all vector types
+type metricVec[T any] struct {
+	name       string
+	help       string
+	labelNames []string
+	newMetric  func() T
+
+	mu      sync.RWMutex
+	metrics map[string]*labeled[T]
+}
+
+type labeled[T any] struct {
+	values []string
+	metric T
+}
+
+func newMetricVec[T any](name, help string, labelNames []string, newMetric func() T) *metricVec[T] {
+	return &metricVec[T]{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		newMetric:  newMetric,
+		metrics:    make(map[string]*labeled[T]),
+	}
+}
+
+// with returns the metric for the label values, creating it on first use
+func (v *metricVec[T]) with(values ...string) T {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labelNames), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	v.mu.RLock()
+	m, ok := v.metrics[key]
+	v.mu.RUnlock()
+	if ok {
+		return m.metric
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if m, ok := v.metrics[key]; ok {
+		return m.metric
+	}
+	m = &labeled[T]{values: append([]string(nil), values...), metric: v.newMetric()}
+	v.metrics[key] = m
+	return m.metric
+}
+
+// each calls fn for every labeled metric, sorted by label values
+func (v *metricVec[T]) each(fn func(labels string, metric T)) {
+	v.mu.RLock()
+	keys := make([]string, 0, len(v.metrics))
+	for key := range v.metrics {
+		keys = append(keys, key)
+	}
+	metrics := make(map[string]*labeled[T], len(v.metrics))
+	for key, m := range v.metrics {
+		metrics[key] = m
+	}
+	v.mu.RUnlock()
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		m := metrics[key]
+		fn(formatLabels(v.labelNames, m.values), m.metric)
+	}
+}
+
+func (v *metricVec[T]) writeHeader(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, kind)
+}
+
+// labelEscaper escapes label values the way the text exposition format
+// defines; everything else, including non-ASCII, is written as is
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatLabels renders {name="value",...}
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// withLabel appends an extra label to a rendered label set
+func withLabel(labels, name, value string) string {
+	extra := name + `="` + labelEscaper.Replace(value) + `"`
+	if labels == "" {
+		return "{" + extra + "}"
+	}
+	return labels[:len(labels)-1] + "," + extra + "}"
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+// atomicFloat is a float64 updated with compare-and-swap
+type atomicFloat struct {
+	bits atomic.Uint64
+}
+
+func (f *atomicFloat) Add(delta float64) {
+	for {
+		old := f.bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if f.bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Set(v float64) {
+	f.bits.Store(math.Float64bits(v))
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(f.bits.Load())
+}
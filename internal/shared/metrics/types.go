@@ -0,0 +1,215 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc increments the counter by one
+func (c *Counter) Inc() { c.value.Add(1) }
+
+// Add increments the counter by delta, which must not be negative
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.value.Add(delta)
+}
+
+// Value returns the current count
+func (c *Counter) Value() float64 { return c.value.Load() }
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	vec *metricVec[*Counter]
+}
+
+// NewCounterVec creates a counter vector and registers it in DefaultRegistry
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{vec: newMetricVec(name, help, labelNames, func() *Counter { return &Counter{} })}
+	DefaultRegistry.Register(c)
+	return c
+}
+
+// With returns the counter for the given label values
+func (c *CounterVec) With(labelValues ...string) *Counter { return c.vec.with(labelValues...) }
+
+// Name implements Collector
+func (c *CounterVec) Name() string { return c.vec.name }
+
+// Write implements Collector
+func (c *CounterVec) Write(w io.Writer) {
+	c.vec.writeHeader(w, "counter")
+	c.vec.each(func(labels string, m *Counter) {
+		fmt.Fprintf(w, "%s%s %s\n", c.vec.name, labels, formatFloat(m.Value()))
+	})
+}
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	value atomicFloat
+}
+
+// Set sets the gauge
+func (g *Gauge) Set(v float64) { g.value.Set(v) }
+
+// Add adds delta to the gauge
+func (g *Gauge) Add(delta float64) { g.value.Add(delta) }
+
+// Inc increments the gauge by one
+func (g *Gauge) Inc() { g.value.Add(1) }
+
+// Dec decrements the gauge by one
+func (g *Gauge) Dec() { g.value.Add(-1) }
+
+// Value returns the current value
+func (g *Gauge) Value() float64 { return g.value.Load() }
+
+// GaugeVec is a gauge partitioned by labels
+type GaugeVec struct {
+	vec *metricVec[*Gauge]
+}
+
+// NewGaugeVec creates a gauge vector and registers it in DefaultRegistry
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{vec: newMetricVec(name, help, labelNames, func() *Gauge { return &Gauge{} })}
+	DefaultRegistry.Register(g)
+	return g
+}
+
+// With returns the gauge for the given label values
+func (g *GaugeVec) With(labelValues ...string) *Gauge { return g.vec.with(labelValues...) }
+
+// Name implements Collector
+func (g *GaugeVec) Name() string { return g.vec.name }
+
+// Write implements Collector
+func (g *GaugeVec) Write(w io.Writer) {
+	g.vec.writeHeader(w, "gauge")
+	g.vec.each(func(labels string, m *Gauge) {
+		fmt.Fprintf(w, "%s%s %s\n", g.vec.name, labels, formatFloat(m.Value()))
+	})
+}
+
+// GaugeFunc is a gauge whose value is computed at scrape time
+type GaugeFunc struct {
+	name string
+	help string
+	fn   func() float64
+}
+
+// NewGaugeFunc creates a computed gauge and registers it in DefaultRegistry
+func NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, fn: fn}
+	DefaultRegistry.Register(g)
+	return g
+}
+
+// Name implements Collector
+func (g *GaugeFunc) Name() string { return g.name }
+
+// Write implements Collector
+func (g *GaugeFunc) Write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", g.name, g.help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", g.name)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
+
+// DefaultBuckets are latency buckets in seconds suited to request handling
+var DefaultBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations into cumulative buckets
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// Observe records a value
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// ObserveDuration records a duration in seconds
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+// snapshot returns cumulative bucket counts, sum and count
+func (h *Histogram) snapshot() ([]uint64, float64, uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cumulative := make([]uint64, len(h.counts))
+	var running uint64
+	for i, c := range h.counts {
+		running += c
+		cumulative[i] = running
+	}
+	return cumulative, h.sum, h.count
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	vec     *metricVec[*Histogram]
+	buckets []float64
+}
+
+// NewHistogramVec creates a histogram vector and registers it in
+// DefaultRegistry. Buckets must be sorted; nil means DefaultBuckets.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramVec{
+		vec:     newMetricVec(name, help, labelNames, func() *Histogram { return newHistogram(buckets) }),
+		buckets: buckets,
+	}
+	DefaultRegistry.Register(h)
+	return h
+}
+
+// With returns the histogram for the given label values
+func (h *HistogramVec) With(labelValues ...string) *Histogram { return h.vec.with(labelValues...) }
+
+// Name implements Collector
+func (h *HistogramVec) Name() string { return h.vec.name }
+
+// Write implements Collector
+func (h *HistogramVec) Write(w io.Writer) {
+	h.vec.writeHeader(w, "histogram")
+	h.vec.each(func(labels string, m *Histogram) {
+		cumulative, sum, count := m.snapshot()
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.vec.name, withLabel(labels, "le", formatFloat(upper)), cumulative[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.vec.name, withLabel(labels, "le", "+Inf"), count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.vec.name, labels, formatFloat(sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.vec.name, labels, count)
+	})
+}
@@ -0,0 +1,393 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// lockKey is the advisory lock held while migrating, so concurrent
+// deployments don't apply migrations twice
+const lockKey int64 = 7_414_001
+
+// Migration is a single schema change. SQL migrations come from the
+// embedded migrations directory; Go migrations can be registered for
+// changes that are generated at runtime.
+type Migration struct {
+	Version int64
+	Name    string
+
+	// UpSQL/DownSQL are used for file migrations
+	UpSQL   string
+	DownSQL string
+
+	// Up/Down are used for Go migrations and take precedence over SQL
+	Up   func(ctx context.Context, tx pgx.Tx) error
+	Down func(ctx context.Context, tx pgx.Tx) error
+}
+
+// checksum identifies the migration content to detect edited migrations
+func (m *Migration) checksum() string {
+	if m.Up != nil {
+		return "go"
+	}
+	sum := sha256.Sum256([]byte(m.UpSQL))
+	return hex.EncodeToString(sum[:])
+}
+
+// Status reports whether a migration has been applied
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Modified  bool       `json:"modified,omitempty"`
+}
+
+// Migrator applies migrations to a database
+type Migrator struct {
+	pool       *pgxpool.Pool
+	logger     logger.Logger
+	migrations []*Migration
+}
+
+// NewMigrator creates a migrator with the embedded SQL migrations
+func NewMigrator(pool *pgxpool.Pool, log logger.Logger) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationsFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		pool:       pool,
+		logger:     log,
+		migrations: migrations,
+	}, nil
+}
+
+// Register adds a Go migration. Versions must be unique across SQL and
+// Go migrations.
+func (m *Migrator) Register(migration Migration) error {
+	for _, existing := range m.migrations {
+		if existing.Version == migration.Version {
+			return fmt.Errorf("migration version %d already registered (%s)", migration.Version, existing.Name)
+		}
+	}
+	m.migrations = append(m.migrations, &migration)
+	sort.Slice(m.migrations, func(i, j int) bool { return m.migrations[i].Version < m.migrations[j].Version })
+	return nil
+}
+
+// Up applies all pending migrations, each in its own transaction.
+// It returns the number of migrations applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+
+			start := time.Now()
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			applied++
+
+			m.logger.Info("migration applied",
+				logger.Int64("version", mig.Version),
+				logger.String("name", mig.Name),
+				logger.Duration("duration", time.Since(start)),
+			)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the last steps applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	rolledBack := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == nil && mig.DownSQL == "" {
+				return fmt.Errorf("migration %d_%s has no down migration", mig.Version, mig.Name)
+			}
+
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return fmt.Errorf("rollback of %d_%s failed: %w", mig.Version, mig.Name, err)
+			}
+			rolledBack++
+
+			m.logger.Info("migration rolled back",
+				logger.Int64("version", mig.Version),
+				logger.String("name", mig.Name),
+			)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	done, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if rec, ok := done[mig.Version]; ok {
+			appliedAt := rec.appliedAt
+			st.AppliedAt = &appliedAt
+			st.Modified = rec.checksum != mig.checksum()
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// Pending reports how many migrations have not been applied
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, st := range statuses {
+		if st.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration lock
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx is done
+		_, _ = conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+	}()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// ensureTable creates the bookkeeping table
+func ensureTable(ctx context.Context, conn *pgxpool.Conn) error {
+	_, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+type appliedRecord struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// appliedVersions reads the applied migrations
+func (m *Migrator) appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int64]appliedRecord, error) {
+	rows, err := conn.Query(ctx, "SELECT version, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int64]appliedRecord)
+	for rows.Next() {
+		var version int64
+		var rec appliedRecord
+		if err := rows.Scan(&version, &rec.checksum, &rec.appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = rec
+	}
+	return done, rows.Err()
+}
+
+// apply runs one migration and records it in the same transaction
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, mig *Migration) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if mig.Up != nil {
+			if err := mig.Up(ctx, tx); err != nil {
+				return err
+			}
+		} else if _, err := tx.Exec(ctx, mig.UpSQL); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx,
+			"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			mig.Version, mig.Name, mig.checksum(),
+		)
+		return err
+	})
+}
+
+// revert rolls back one migration and removes its record
+func (m *Migrator) revert(ctx context.Context, conn *pgxpool.Conn, mig *Migration) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if mig.Down != nil {
+			if err := mig.Down(ctx, tx); err != nil {
+				return err
+			}
+		} else if _, err := tx.Exec(ctx, mig.DownSQL); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Version)
+		return err
+	})
+}
+
+// tenantDirective marks a table for tenant isolation in an up file:
+//
+//	-- migrate:tenant-table orders
+//
+// The table needs a tenant_id column. The migrator appends row-level
+// security policies that limit it to the transaction's app.tenant_id,
+// and prepends their removal to the down file.
+var tenantDirective = regexp.MustCompile(`(?m)^--\s*migrate:tenant-table\s+([a-z_][a-z0-9_]*)\s*$`)
+
+// tenantPolicySQL isolates a table by tenant. FORCE applies the policy
+// to the table owner too, which the application usually connects as.
+// Superusers and BYPASSRLS roles skip it regardless.
+func tenantPolicySQL(table string) string {
+	return fmt.Sprintf(`
+ALTER TABLE %[1]s ENABLE ROW LEVEL SECURITY;
+ALTER TABLE %[1]s FORCE ROW LEVEL SECURITY;
+CREATE POLICY %[1]s_tenant_isolation ON %[1]s
+    USING (tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (tenant_id = current_setting('app.tenant_id', true));
+`, table)
+}
+
+// dropTenantPolicySQL undoes tenantPolicySQL
+func dropTenantPolicySQL(table string) string {
+	return fmt.Sprintf(`DROP POLICY IF EXISTS %[1]s_tenant_isolation ON %[1]s;
+ALTER TABLE %[1]s NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE %[1]s DISABLE ROW LEVEL SECURITY;
+`, table)
+}
+
+// expandTenantTables applies tenant directives to a migration
+func expandTenantTables(mig *Migration) {
+	for _, match := range tenantDirective.FindAllStringSubmatch(mig.UpSQL, -1) {
+		table := match[1]
+		mig.UpSQL += tenantPolicySQL(table)
+		mig.DownSQL = dropTenantPolicySQL(table) + mig.DownSQL
+	}
+}
+
+// fileNamePattern matches 0001_create_users.up.sql / .down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// loadMigrations reads SQL migrations from dir
+func loadMigrations(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by %s and %s", version, mig.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			mig.UpSQL = string(data)
+		} else {
+			mig.DownSQL = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.UpSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		expandTenantTables(mig)
+		migrations = append(migrations, mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	if len(migrations) == 0 {
+		return nil, errors.New("no migrations found")
+	}
+	return migrations, nil
+}
@@ -0,0 +1,14 @@
+CREATE TABLE instruments (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    symbol         TEXT NOT NULL UNIQUE,
+    name           TEXT NOT NULL,
+    asset_class    TEXT NOT NULL CHECK (asset_class IN ('equity', 'etf', 'fx', 'crypto', 'future', 'bond')),
+    currency       CHAR(3) NOT NULL,
+    tick_size      NUMERIC NOT NULL CHECK (tick_size > 0),
+    lot_size       NUMERIC NOT NULL CHECK (lot_size > 0),
+    price_scale    SMALLINT NOT NULL CHECK (price_scale BETWEEN 0 AND 18),
+    quantity_scale SMALLINT NOT NULL CHECK (quantity_scale BETWEEN 0 AND 18),
+    status         TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'halted', 'delisted')),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,29 @@
+CREATE TABLE jobs (
+    id           BIGSERIAL PRIMARY KEY,
+    queue        TEXT NOT NULL DEFAULT 'default',
+    kind         TEXT NOT NULL,
+    payload      JSONB NOT NULL DEFAULT '{}',
+    priority     SMALLINT NOT NULL DEFAULT 0,
+    status       TEXT NOT NULL DEFAULT 'pending'
+                 CHECK (status IN ('pending', 'running', 'succeeded', 'dead')),
+    attempts     INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 10 CHECK (max_attempts > 0),
+    run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    unique_key   TEXT,
+    last_error   TEXT,
+    locked_by    TEXT,
+    locked_at    TIMESTAMPTZ,
+    finished_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Workers fetch the highest priority due job per queue
+CREATE INDEX jobs_fetch_idx ON jobs (queue, priority DESC, run_at, id) WHERE status = 'pending';
+
+-- Unique jobs: only one pending or running job per kind and key
+CREATE UNIQUE INDEX jobs_unique_idx ON jobs (kind, unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('pending', 'running');
+
+CREATE INDEX jobs_running_idx ON jobs (locked_at) WHERE status = 'running';
+CREATE INDEX jobs_finished_idx ON jobs (finished_at) WHERE finished_at IS NOT NULL;
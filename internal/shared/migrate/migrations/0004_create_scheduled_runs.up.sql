@@ -0,0 +1,16 @@
+-- One row per fired occurrence of a recurring task. The unique key makes
+-- firing idempotent across leader changes and restarts.
+CREATE TABLE scheduled_runs (
+    id           BIGSERIAL PRIMARY KEY,
+    task         TEXT NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    status       TEXT NOT NULL DEFAULT 'running'
+                 CHECK (status IN ('running', 'succeeded', 'failed', 'interrupted')),
+    instance     TEXT NOT NULL,
+    error        TEXT,
+    started_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at  TIMESTAMPTZ,
+    UNIQUE (task, scheduled_at)
+);
+
+CREATE INDEX scheduled_runs_task_idx ON scheduled_runs (task, scheduled_at DESC);
@@ -0,0 +1,38 @@
+-- Append-only record of security and trading actions. Each row's hash
+-- covers its fields and the previous row's hash, so editing or removing
+-- a row breaks the chain from that point on.
+CREATE TABLE audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    actor_type  TEXT NOT NULL,
+    actor_id    TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    entity_type TEXT NOT NULL DEFAULT '',
+    entity_id   TEXT NOT NULL DEFAULT '',
+    request_id  TEXT NOT NULL DEFAULT '',
+    ip          TEXT NOT NULL DEFAULT '',
+    metadata    JSONB NOT NULL DEFAULT '{}',
+    prev_hash   BYTEA,
+    hash        BYTEA NOT NULL
+);
+
+CREATE INDEX audit_events_actor_idx ON audit_events (actor_type, actor_id, occurred_at DESC);
+CREATE INDEX audit_events_entity_idx ON audit_events (entity_type, entity_id, occurred_at DESC);
+CREATE INDEX audit_events_action_idx ON audit_events (action, occurred_at DESC);
+CREATE INDEX audit_events_occurred_idx ON audit_events (occurred_at DESC);
+
+-- Reject changes through the application role; the hash chain catches
+-- anyone who bypasses this
+CREATE FUNCTION audit_events_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_immutable();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_immutable();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS users_email_key;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+CREATE UNIQUE INDEX users_email_key ON users (lower(email));
+DROP TABLE IF EXISTS tenants;
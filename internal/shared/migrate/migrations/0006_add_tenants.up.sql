@@ -0,0 +1,23 @@
+-- Brokerage clients. settings holds per-tenant overrides of config
+-- sections, e.g. {"trading": {"taker_fee_bps": 15}}.
+CREATE TABLE tenants (
+    id         TEXT PRIMARY KEY CHECK (id ~ '^[a-z0-9][a-z0-9-]{1,62}$'),
+    name       TEXT NOT NULL,
+    settings   JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Existing data belongs to the default tenant
+INSERT INTO tenants (id, name) VALUES ('default', 'Default');
+
+ALTER TABLE users ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+
+-- New rows take the transaction's tenant; inserts without one fail
+ALTER TABLE users ALTER COLUMN tenant_id SET DEFAULT current_setting('app.tenant_id', true);
+
+-- Emails are unique per tenant
+DROP INDEX users_email_key;
+CREATE UNIQUE INDEX users_email_key ON users (tenant_id, lower(email));
+
+-- migrate:tenant-table users
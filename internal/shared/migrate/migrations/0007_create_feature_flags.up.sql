@@ -0,0 +1,12 @@
+-- Feature flags are global; targeting by tenant is done with
+-- allow_tenants rather than row-level security
+CREATE TABLE feature_flags (
+    key             TEXT PRIMARY KEY CHECK (key ~ '^[a-z0-9][a-z0-9_.-]*$'),
+    description     TEXT NOT NULL DEFAULT '',
+    enabled         BOOLEAN NOT NULL DEFAULT false,
+    rollout_percent SMALLINT NOT NULL DEFAULT 0 CHECK (rollout_percent BETWEEN 0 AND 100),
+    allow_users     TEXT[] NOT NULL DEFAULT '{}',
+    allow_tenants   TEXT[] NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
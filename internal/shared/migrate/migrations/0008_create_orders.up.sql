@@ -0,0 +1,50 @@
+-- Orders and their history. IDs are generated by the application (see
+-- internal/shared/id) so they sort by creation time without a sequence.
+CREATE TABLE orders (
+    id              BIGINT PRIMARY KEY,
+    tenant_id       TEXT NOT NULL DEFAULT current_setting('app.tenant_id', true) REFERENCES tenants (id),
+    user_id         UUID NOT NULL REFERENCES users (id),
+    client_order_id TEXT CHECK (length(client_order_id) BETWEEN 1 AND 64),
+    symbol          TEXT NOT NULL REFERENCES instruments (symbol),
+    side            TEXT NOT NULL CHECK (side IN ('buy', 'sell')),
+    type            TEXT NOT NULL CHECK (type IN ('market', 'limit')),
+    time_in_force   TEXT NOT NULL CHECK (time_in_force IN ('gtc', 'day', 'gtd', 'ioc', 'fok')),
+    price           NUMERIC CHECK (price > 0),
+    quantity        NUMERIC NOT NULL CHECK (quantity > 0),
+    filled_quantity NUMERIC NOT NULL DEFAULT 0 CHECK (filled_quantity >= 0 AND filled_quantity <= quantity),
+    average_price   NUMERIC,
+    status          TEXT NOT NULL CHECK (status IN ('new', 'accepted', 'partially_filled', 'filled',
+                                                    'cancelled', 'rejected', 'expired')),
+    reject_reason   TEXT NOT NULL DEFAULT '',
+    expires_at      TIMESTAMPTZ,
+    version         INTEGER NOT NULL DEFAULT 1,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    CHECK ((type = 'limit') = (price IS NOT NULL)),
+    CHECK ((time_in_force = 'gtd') = (expires_at IS NOT NULL))
+);
+
+-- Client order IDs deduplicate retried submissions per user
+CREATE UNIQUE INDEX orders_client_order_id_key ON orders (tenant_id, user_id, client_order_id)
+    WHERE client_order_id IS NOT NULL;
+CREATE INDEX orders_user_created_idx ON orders (tenant_id, user_id, created_at DESC, id DESC);
+CREATE INDEX orders_open_idx ON orders (tenant_id, user_id)
+    WHERE status IN ('new', 'accepted', 'partially_filled');
+
+-- migrate:tenant-table orders
+
+-- One row per state change or amendment, never updated
+CREATE TABLE order_events (
+    id          BIGINT PRIMARY KEY,
+    tenant_id   TEXT NOT NULL DEFAULT current_setting('app.tenant_id', true) REFERENCES tenants (id),
+    order_id    BIGINT NOT NULL REFERENCES orders (id),
+    type        TEXT NOT NULL,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status   TEXT NOT NULL,
+    data        JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX order_events_order_idx ON order_events (order_id, id);
+
+-- migrate:tenant-table order_events
@@ -0,0 +1,3 @@
+-- Events appended since the up migration no longer verify as one chain
+DROP INDEX IF EXISTS audit_events_chain_idx;
+ALTER TABLE audit_events DROP COLUMN IF EXISTS tenant_id;
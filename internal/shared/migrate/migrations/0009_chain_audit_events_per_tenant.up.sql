@@ -0,0 +1,7 @@
+-- Each tenant's events form their own hash chain, so appends only
+-- serialize with other appends of the same tenant. Events from before
+-- this migration, and those recorded outside any tenant, form the ''
+-- chain.
+ALTER TABLE audit_events ADD COLUMN tenant_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX audit_events_chain_idx ON audit_events (tenant_id, id DESC);
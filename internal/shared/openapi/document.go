@@ -0,0 +1,157 @@
+package openapi
+
+import "encoding/json"
+
+// Version is the OpenAPI specification version produced by this package
+const Version = "3.1.0"
+
+// Document is an OpenAPI 3.1 document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+	Tags       []Tag                `json:"tags,omitempty"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is a base URL the API is served from
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag groups operations in the docs
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds reusable schemas
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// PathItem holds the operations of one path
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+}
+
+// operation returns the operation slot for an HTTP method
+func (p *PathItem) operation(method string) **Operation {
+	switch method {
+	case "GET":
+		return &p.Get
+	case "PUT":
+		return &p.Put
+	case "POST":
+		return &p.Post
+	case "DELETE":
+		return &p.Delete
+	case "PATCH":
+		return &p.Patch
+	default:
+		return nil
+	}
+}
+
+// Operation is a single API operation
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query, header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes an operation's request payload
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes one response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a payload
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema is the JSON Schema subset used for DTOs
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // bool or *Schema
+	Items                *Schema            `json:"items,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+
+	// Nullable also allows null, written as type [T, "null"]
+	Nullable bool `json:"-"`
+}
+
+// MarshalJSON writes nullable schemas the OpenAPI 3.1 way
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	switch {
+	case !s.Nullable:
+		return json.Marshal(plain(s))
+	case s.Ref != "":
+		// Siblings of $ref would apply to null too; wrap instead
+		ref := plain(s)
+		ref.Description = ""
+		return json.Marshal(struct {
+			AnyOf       []interface{} `json:"anyOf"`
+			Description string        `json:"description,omitempty"`
+		}{[]interface{}{ref, map[string]string{"type": "null"}}, s.Description})
+	case s.Type == "":
+		// Untyped schemas already accept null
+		return json.Marshal(plain(s))
+	default:
+		return json.Marshal(struct {
+			plain
+			Type []string `json:"type"`
+		}{plain(s), []string{s.Type, "null"}})
+	}
+}
+
+// SchemaProvider is implemented by types that describe their own schema,
+// e.g. value types marshalled as strings
+type SchemaProvider interface {
+	OpenAPISchema() *Schema
+}
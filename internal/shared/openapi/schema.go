@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	jsonNumberType     = reflect.TypeOf(json.Number(""))
+	rawMessageType     = reflect.TypeOf(json.RawMessage(nil))
+	schemaProviderType = reflect.TypeOf((*SchemaProvider)(nil)).Elem()
+)
+
+// schemaGenerator builds schemas from Go types, collecting named struct
+// types as reusable components
+type schemaGenerator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaFor returns the schema of v's type; v may be a value or a reflect.Type
+func (g *schemaGenerator) schemaFor(v interface{}) *Schema {
+	if v == nil {
+		return nil
+	}
+	if t, ok := v.(reflect.Type); ok {
+		return g.schema(t)
+	}
+	return g.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema for t. Named structs become $refs.
+//
+// Struct fields use these tags:
+//
+//	json:"name,omitempty"  name; omitempty or a pointer makes the field optional
+//	doc:"..."              description
+//	enum:"a,b,c"           allowed values
+//	example:"..."          example value
+//	min:"0" max:"100"      numeric bounds
+//	maxLength:"64"         string length limit
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Types that describe themselves
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(SchemaProvider).OpenAPISchema()
+	}
+	if reflect.PointerTo(t).Implements(schemaProviderType) {
+		return reflect.New(t).Interface().(SchemaProvider).OpenAPISchema()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case jsonNumberType:
+		return &Schema{Type: "number"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.componentName(t)}
+	default:
+		// interface{} and anything else accepts any value
+		return &Schema{}
+	}
+}
+
+// componentName registers t as a component and returns its name
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := genericName(t.Name())
+	if _, taken := g.components[name]; taken {
+		// Same type name in another package
+		pkg := []rune(path.Base(t.PkgPath()))
+		pkg[0] = unicode.ToUpper(pkg[0])
+		name = string(pkg) + name
+	}
+
+	// Reserve the name before recursing to support self-references
+	g.names[t] = name
+	g.components[name] = &Schema{}
+	*g.components[name] = *g.structSchema(t)
+	return name
+}
+
+// genericName turns an instantiated generic type name such as
+// Page[github.com/x/orders.Order] into a valid component name, PageOrder
+func genericName(name string) string {
+	base, args, ok := strings.Cut(name, "[")
+	if !ok {
+		return name
+	}
+	var b strings.Builder
+	b.WriteString(base)
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		if i := strings.LastIndexAny(arg, "./"); i >= 0 {
+			arg = arg[i+1:]
+		}
+		for _, r := range arg {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// structSchema builds an inline object schema from struct fields
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds t's exported fields to s, flattening embedded structs
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// Embedded structs without a JSON name are flattened
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.schema(f.Type)
+		nullable := f.Type.Kind() == reflect.Pointer
+		if hasFieldTags(f.Tag) || nullable {
+			// Don't mutate shared component refs; wrap in a copy
+			copied := *fs
+			fs = &copied
+			applyFieldTags(fs, f.Tag)
+			// A nil pointer is valid input, and omitted or null alike
+			fs.Nullable = nullable
+		}
+		s.Properties[name] = fs
+
+		optional := strings.Contains(opts, "omitempty") || f.Type.Kind() == reflect.Pointer
+		if !optional {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasFieldTags(tag reflect.StructTag) bool {
+	for _, key := range []string{"doc", "enum", "example", "min", "max", "maxLength"} {
+		if _, ok := tag.Lookup(key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyFieldTags applies documentation and constraint tags to s
+func applyFieldTags(s *Schema, tag reflect.StructTag) {
+	if doc, ok := tag.Lookup("doc"); ok {
+		s.Description = doc
+	}
+	if enum, ok := tag.Lookup("enum"); ok {
+		for _, v := range strings.Split(enum, ",") {
+			s.Enum = append(s.Enum, strings.TrimSpace(v))
+		}
+	}
+	if example, ok := tag.Lookup("example"); ok {
+		s.Example = example
+	}
+	if v, ok := tag.Lookup("min"); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			s.Minimum = &f
+		}
+	}
+	if v, ok := tag.Lookup("max"); ok {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			s.Maximum = &f
+		}
+	}
+	if v, ok := tag.Lookup("maxLength"); ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			s.MaxLength = &n
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// Route documents one operation. Request and Responses take DTO values
+// (e.g. CreateOrderRequest{}) whose types are turned into schemas.
+type Route struct {
+	Summary     string
+	Description string
+	Tags        []string
+	OperationID string
+	Deprecated  bool
+
+	// Params documents path and query parameters. Path parameters found
+	// in the pattern are added automatically if not listed.
+	Params []Parameter
+
+	// Request is the JSON request body DTO, nil for none
+	Request interface{}
+
+	// Responses maps status codes to response DTOs; a nil DTO means no body
+	Responses map[int]interface{}
+}
+
+// Spec collects route documentation and builds the OpenAPI document from
+// the routes actually registered on a chi router
+type Spec struct {
+	info     Info
+	basePath string
+	tags     []Tag
+
+	mu     sync.RWMutex
+	routes map[string]Route // keyed by "METHOD path"
+
+	buildOnce sync.Once
+	doc       *Document
+	docJSON   []byte
+	buildErr  error
+}
+
+// NewSpec creates a spec for the routes mounted under basePath (e.g. /api/v1)
+func NewSpec(info Info, basePath string) *Spec {
+	return &Spec{
+		info:     info,
+		basePath: strings.TrimSuffix(basePath, "/"),
+		routes:   make(map[string]Route),
+	}
+}
+
+// AddTag documents an operation tag
+func (s *Spec) AddTag(name, description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags = append(s.tags, Tag{Name: name, Description: description})
+}
+
+// Describe documents the operation for method and path, relative to the
+// spec's base path, using chi pattern syntax (e.g. /orders/{id})
+func (s *Spec) Describe(method, path string, route Route) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[method+" "+normalizePattern(path)] = route
+}
+
+// Group returns a Describer that prefixes paths, for modules mounted
+// below the base path
+func (s *Spec) Group(prefix string) *Group {
+	return &Group{spec: s, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+// Group documents routes of a mounted sub-router
+type Group struct {
+	spec   *Spec
+	prefix string
+}
+
+// Describe documents an operation relative to the group prefix
+func (g *Group) Describe(method, path string, route Route) {
+	g.spec.Describe(method, g.prefix+path, route)
+}
+
+// Build walks router and builds the document once. Routes outside the
+// base path are ignored; undocumented routes get a minimal operation.
+func (s *Spec) Build(router chi.Routes) (*Document, error) {
+	s.buildOnce.Do(func() {
+		s.doc, s.buildErr = s.build(router)
+		if s.buildErr == nil {
+			s.docJSON, s.buildErr = json.MarshalIndent(s.doc, "", "  ")
+		}
+	})
+	return s.doc, s.buildErr
+}
+
+func (s *Spec) build(router chi.Routes) (*Document, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	gen := newSchemaGenerator()
+	gen.schemaFor(apperrors.ProblemDetails{})
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Servers: []Server{{URL: s.basePath}},
+		Paths:   make(map[string]*PathItem),
+		Tags:    s.tags,
+	}
+
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		route = normalizePattern(route)
+		if !strings.HasPrefix(route, s.basePath+"/") {
+			return nil
+		}
+		path := strings.TrimPrefix(route, s.basePath)
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+		}
+		slot := item.operation(method)
+		if slot == nil {
+			return nil // HEAD, OPTIONS, etc.
+		}
+
+		*slot = s.operation(gen, method, path, s.routes[method+" "+path])
+		doc.Paths[path] = item
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk routes: %w", err)
+	}
+
+	doc.Components.Schemas = gen.components
+	return doc, nil
+}
+
+// operation converts a Route into a document operation
+func (s *Spec) operation(gen *schemaGenerator, method, path string, route Route) *Operation {
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Parameters:  append([]Parameter(nil), route.Params...),
+		Deprecated:  route.Deprecated,
+		Responses:   make(map[string]*Response),
+	}
+	if op.OperationID == "" {
+		op.OperationID = operationID(method, path)
+	}
+
+	// Path parameters from the pattern
+	for _, name := range pathParams(path) {
+		if !hasParam(op.Parameters, name, "path") {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: gen.schemaFor(route.Request)},
+			},
+		}
+	}
+
+	for status, dto := range route.Responses {
+		resp := &Response{Description: http.StatusText(status)}
+		if dto != nil {
+			resp.Content = map[string]MediaType{
+				"application/json": {Schema: gen.schemaFor(dto)},
+			}
+		}
+		op.Responses[fmt.Sprint(status)] = resp
+	}
+	if len(route.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	// Every operation can fail with a problem+json error
+	op.Responses["default"] = &Response{
+		Description: "Error",
+		Content: map[string]MediaType{
+			apperrors.ContentTypeProblemJSON: {Schema: &Schema{Ref: "#/components/schemas/ProblemDetails"}},
+		},
+	}
+	return op
+}
+
+// JSONHandler serves the document as JSON, building it from router on
+// first request
+func (s *Spec) JSONHandler(router chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, err := s.Build(router); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(s.docJSON)
+	}
+}
+
+//go:embed docs.html
+var docsPage []byte
+
+// DocsHandler serves the bundled docs page, which renders the document
+// from the openapi.json sibling URL
+func DocsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(docsPage)
+	}
+}
+
+var paramPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// normalizePattern converts a chi pattern to an OpenAPI path template:
+// regexp constraints are dropped and trailing slashes removed
+func normalizePattern(pattern string) string {
+	pattern = paramPattern.ReplaceAllString(pattern, "{$1}")
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+// pathParams returns the parameter names in a path template
+func pathParams(path string) []string {
+	var names []string
+	for _, m := range paramPattern.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+func hasParam(params []Parameter, name, in string) bool {
+	for _, p := range params {
+		if p.Name == name && p.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+// operationID derives an ID like getOrdersById from method and path
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" {
+			continue
+		}
+		if strings.HasPrefix(seg, "{") {
+			b.WriteString("By")
+			seg = strings.Trim(seg, "{}")
+		}
+		for _, part := range strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// sortedPaths returns document paths with static segments first, so
+// /orders/open matches before /orders/{id}
+func sortedPaths(doc *Document) []string {
+	paths := make([]string, 0, len(doc.Paths))
+	for p := range doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		ci, cj := strings.Count(paths[i], "{"), strings.Count(paths[j], "{")
+		if ci != cj {
+			return ci < cj
+		}
+		return paths[i] < paths[j]
+	})
+	return paths
+}
@@ -0,0 +1,390 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/request"
+)
+
+// Middleware validates incoming requests against the document built from
+// router. Invalid requests get a VALIDATION_ERROR response listing each
+// field violation. When validateResponses is set, JSON responses are
+// checked too and mismatches are logged; use it in development only.
+func (s *Spec) Middleware(router chi.Routes, log logger.Logger, validateResponses bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			doc, err := s.Build(router)
+			if err != nil {
+				log.Error("openapi document unavailable, skipping validation", logger.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			path, op := s.findOperation(doc, r.Method, r.URL.Path)
+			if op == nil {
+				// Unknown routes are left to the router
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			v := &validator{doc: doc}
+			if err := v.validateRequest(r, op); err != nil {
+				apperrors.WriteError(w, r, err, log)
+				return
+			}
+
+			if !validateResponses {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Tee the response so it can be checked after the handler
+			var buf bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+			next.ServeHTTP(ww, r)
+
+			if violations := v.validateResponse(op, ww.Status(), ww.Header().Get("Content-Type"), buf.Bytes()); len(violations) > 0 {
+				log.Warn("response does not match OpenAPI spec",
+					logger.String("method", r.Method),
+					logger.String("path", path),
+					logger.Int("status", ww.Status()),
+					logger.Any("violations", violations),
+				)
+			}
+		})
+	}
+}
+
+// findOperation matches a request path against the document paths
+func (s *Spec) findOperation(doc *Document, method, urlPath string) (string, *Operation) {
+	if !strings.HasPrefix(urlPath, s.basePath+"/") {
+		return "", nil
+	}
+	rel := normalizePattern(strings.TrimPrefix(urlPath, s.basePath))
+	reqSegs := strings.Split(rel, "/")
+
+	for _, path := range sortedPaths(doc) {
+		segs := strings.Split(path, "/")
+		if len(segs) != len(reqSegs) {
+			continue
+		}
+		match := true
+		for i, seg := range segs {
+			if !strings.HasPrefix(seg, "{") && seg != reqSegs[i] {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if slot := doc.Paths[path].operation(method); slot != nil && *slot != nil {
+			return path, *slot
+		}
+	}
+	return "", nil
+}
+
+// validator checks values against document schemas
+type validator struct {
+	doc *Document
+}
+
+// validateRequest checks query parameters and the JSON body. The body is
+// restored for the handler.
+func (v *validator) validateRequest(r *http.Request, op *Operation) error {
+	ve := apperrors.NewValidationError()
+
+	// 1. Query parameters
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		if p.In != "query" {
+			continue
+		}
+		raw, present := query[p.Name]
+		if !present {
+			if p.Required {
+				ve.AddCode(p.Name, apperrors.CodeFieldRequired, nil)
+			}
+			continue
+		}
+		if p.Schema != nil {
+			v.validate(p.Schema, queryValue(p.Schema, raw[0]), p.Name, ve)
+		}
+	}
+
+	// 2. JSON body
+	if op.RequestBody != nil {
+		media, ok := op.RequestBody.Content["application/json"]
+		if ok && media.Schema != nil {
+			if err := request.CheckContentLength(r); err != nil {
+				return err
+			}
+			data, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					return apperrors.New(apperrors.ErrInvalidInput, request.CodeBodyTooLarge, "").
+						WithMeta("limit", maxErr.Limit)
+				}
+				return apperrors.New(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "").WithCause(err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(data))
+
+			// Malformed JSON is left to the handler's decoder, which
+			// reports it precisely
+			if value, ok := decodeValue(data); ok {
+				v.validate(media.Schema, value, "", ve)
+			}
+		}
+	}
+
+	return ve.ErrOrNil()
+}
+
+// validateResponse checks a JSON response body against the schema for
+// its status code
+func (v *validator) validateResponse(op *Operation, status int, contentType string, body []byte) []apperrors.FieldError {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp, ok := op.Responses[strconv.Itoa(status)]
+	if !ok {
+		resp, ok = op.Responses["default"]
+	}
+	if !ok {
+		return []apperrors.FieldError{{Field: "status", Message: fmt.Sprintf("undocumented status %d", status)}}
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	media, ok := resp.Content[strings.TrimSpace(mediaType)]
+	if !ok || media.Schema == nil {
+		return nil
+	}
+
+	value, ok := decodeValue(body)
+	if !ok {
+		return []apperrors.FieldError{{Field: "body", Message: "response is not valid JSON"}}
+	}
+	ve := apperrors.NewValidationError()
+	v.validate(media.Schema, value, "", ve)
+	return ve.Fields
+}
+
+// decodeValue decodes JSON keeping numbers as json.Number
+func decodeValue(data []byte) (interface{}, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// queryValue converts a raw query string to the JSON type its schema expects
+func queryValue(schema *Schema, raw string) interface{} {
+	switch schema.Type {
+	case "integer", "number":
+		return json.Number(raw)
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+	return raw
+}
+
+// resolve follows a component $ref
+func (v *validator) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		resolved, ok := v.doc.Components.Schemas[name]
+		if !ok {
+			return nil
+		}
+		schema = resolved
+	}
+	return schema
+}
+
+// validate checks value against schema, recording violations under path
+func (v *validator) validate(schema *Schema, value interface{}, path string, ve *apperrors.ValidationError) {
+	if schema == nil {
+		return
+	}
+	field := path
+	if field == "" {
+		field = "body"
+	}
+	if value == nil && schema.Nullable {
+		return
+	}
+
+	// Constraints next to a $ref apply in addition to the referenced schema
+	if schema.Ref != "" {
+		v.validate(v.resolve(schema), value, path, ve)
+		v.validateConstraints(schema, value, field, ve)
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			typeMismatch(ve, field, "object")
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				ve.AddCode(join(path, name), apperrors.CodeFieldRequired, nil)
+			}
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fieldValue := obj[name]
+			if prop, ok := schema.Properties[name]; ok {
+				v.validate(prop, fieldValue, join(path, name), ve)
+				continue
+			}
+			switch extra := schema.AdditionalProperties.(type) {
+			case bool:
+				if !extra {
+					ve.AddCode(join(path, name), request.CodeUnknownField, map[string]interface{}{"field": join(path, name)})
+				}
+			case *Schema:
+				v.validate(extra, fieldValue, join(path, name), ve)
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			typeMismatch(ve, field, "array")
+			return
+		}
+		for i, item := range arr {
+			v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), ve)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			typeMismatch(ve, field, "string")
+			return
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			typeMismatch(ve, field, "integer")
+			return
+		}
+		if _, err := n.Int64(); err != nil {
+			typeMismatch(ve, field, "integer")
+			return
+		}
+	case "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			typeMismatch(ve, field, "number")
+			return
+		}
+		if _, err := n.Float64(); err != nil {
+			typeMismatch(ve, field, "number")
+			return
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			typeMismatch(ve, field, "boolean")
+			return
+		}
+	}
+
+	v.validateConstraints(schema, value, field, ve)
+}
+
+// validateConstraints checks enum, format, length and range constraints
+func (v *validator) validateConstraints(schema *Schema, value interface{}, field string, ve *apperrors.ValidationError) {
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ve.AddCode(field, apperrors.CodeFieldInvalid, nil)
+			return
+		}
+	}
+
+	switch val := value.(type) {
+	case string:
+		if schema.MaxLength != nil && len([]rune(val)) > *schema.MaxLength {
+			ve.AddCode(field, apperrors.CodeFieldTooLong, map[string]interface{}{"max": *schema.MaxLength})
+		}
+		if schema.MinLength != nil && len([]rune(val)) < *schema.MinLength {
+			ve.AddCode(field, apperrors.CodeFieldInvalid, nil)
+		}
+		if schema.Pattern != "" {
+			if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(val) {
+				ve.AddCode(field, apperrors.CodeFieldInvalid, nil)
+			}
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, val); err != nil {
+				ve.AddCode(field, apperrors.CodeFieldInvalid, nil)
+			}
+		}
+	case json.Number:
+		f, err := val.Float64()
+		if err != nil {
+			return
+		}
+		if (schema.Minimum != nil && f < *schema.Minimum) || (schema.Maximum != nil && f > *schema.Maximum) {
+			ve.AddCode(field, apperrors.CodeFieldRange, map[string]interface{}{
+				"min": boundString(schema.Minimum),
+				"max": boundString(schema.Maximum),
+			})
+		}
+	}
+}
+
+// typeMismatch records a value of the wrong JSON type
+func typeMismatch(ve *apperrors.ValidationError, field, expected string) {
+	ve.AddCode(field, request.CodeFieldTypeMismatch, map[string]interface{}{"field": field, "expected": expected})
+}
+
+func boundString(bound *float64) string {
+	if bound == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*bound, 'f', -1, 64)
+}
+
+// join builds a dotted field path
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,101 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// cursor is the signed content of a page token
+type cursor struct {
+	// Binding is a hash of the endpoint, sort and filters
+	Binding []byte `json:"b"`
+	// Values are the sort values of the last row, in sort order
+	Values []string `json:"v"`
+}
+
+// signer encodes and verifies cursors with HMAC-SHA256
+type signer struct {
+	key []byte
+}
+
+// newSigner creates a signer. Without a secret a random key is used, so
+// cursors only work against the instance that issued them until restart.
+func newSigner(secret string) *signer {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	return &signer{key: key}
+}
+
+func (s *signer) mac(data []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func bindingHash(q *Query) []byte {
+	sum := sha256.Sum256([]byte(q.binding()))
+	return sum[:8]
+}
+
+// encode returns the token for the position after values
+func (s *signer) encode(q *Query, values []any) (string, error) {
+	c := cursor{Binding: bindingHash(q), Values: make([]string, len(values))}
+	for i, v := range values {
+		c.Values[i] = formatValue(v)
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", apperrors.Wrapf(apperrors.ErrInternal, "failed to encode cursor: %v", err)
+	}
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(data) + "." + enc.EncodeToString(s.mac(data)), nil
+}
+
+// decode verifies a token against q and returns its typed sort values
+func (s *signer) decode(token string, q *Query) ([]any, error) {
+	invalid := apperrors.New(apperrors.ErrInvalidInput, CodeInvalidCursor,
+		"the cursor is invalid, restart from the first page")
+
+	enc := base64.RawURLEncoding
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, invalid
+	}
+	data, err := enc.DecodeString(payload)
+	if err != nil {
+		return nil, invalid
+	}
+	mac, err := enc.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.mac(data)) {
+		return nil, invalid
+	}
+
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, invalid
+	}
+	// A signed cursor from another list, e.g. after the client changed
+	// the sort or filters
+	if !hmac.Equal(c.Binding, bindingHash(q)) || len(c.Values) != len(q.Sort) {
+		return nil, invalid.WithInternal("cursor issued for another query")
+	}
+
+	values := make([]any, len(c.Values))
+	for i, k := range q.Sort {
+		v, err := parseValue(q.spec.field(k.Field).Type, c.Values[i])
+		if err != nil {
+			return nil, invalid
+		}
+		values[i] = v
+	}
+	return values, nil
+}
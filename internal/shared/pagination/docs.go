@@ -0,0 +1,62 @@
+package pagination
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/openapi"
+)
+
+var typeNames = map[Type]string{
+	String:  "string",
+	Int:     "integer",
+	Decimal: "decimal string",
+	Time:    "RFC 3339 timestamp",
+	Bool:    "boolean",
+	UUID:    "UUID",
+}
+
+// Parameters documents the query parameters the spec accepts, for
+// openapi.Route.Params
+func (s *Spec) Parameters() []openapi.Parameter {
+	var sortable []string
+	for _, f := range s.Fields {
+		if f.Sortable {
+			sortable = append(sortable, f.Name)
+		}
+	}
+
+	params := []openapi.Parameter{
+		{Name: "limit", In: "query", Description: "Page size", Schema: &openapi.Schema{Type: "integer", Format: "int32"}},
+		{Name: "cursor", In: "query", Description: "next_cursor of the previous page", Schema: &openapi.Schema{Type: "string"}},
+	}
+	if len(sortable) > 0 {
+		params = append(params, openapi.Parameter{
+			Name: "sort",
+			In:   "query",
+			Description: fmt.Sprintf("Comma-separated fields, - prefix for descending: %s. Default %s",
+				strings.Join(sortable, ", "), s.DefaultSort),
+			Schema: &openapi.Schema{Type: "string"},
+		})
+	}
+
+	for _, f := range s.Fields {
+		for _, op := range f.Ops {
+			name, desc := f.Name+"["+string(op)+"]", fmt.Sprintf("%s %s, %s", f.Name, op, typeNames[f.Type])
+			if op == Eq {
+				name = f.Name
+			}
+			if op == In {
+				desc += " list, comma-separated"
+			}
+			schema := &openapi.Schema{Type: "string"}
+			if len(f.Values) > 0 && op != In {
+				for _, v := range f.Values {
+					schema.Enum = append(schema.Enum, v)
+				}
+			}
+			params = append(params, openapi.Parameter{Name: name, In: "query", Description: desc, Schema: schema})
+		}
+	}
+	return params
+}
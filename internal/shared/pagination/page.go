@@ -0,0 +1,44 @@
+package pagination
+
+// Page is the envelope every list endpoint returns
+type Page[T any] struct {
+	Data       []T  `json:"data"`
+	Pagination Info `json:"pagination"`
+}
+
+// Info describes where a page sits in its list
+type Info struct {
+	Limit int `json:"limit"`
+	// NextCursor fetches the following page; empty on the last one
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// KeyFunc returns an item's value for a sort field, of the field's type
+type KeyFunc[T any] func(item T, field string) any
+
+// NewPage builds a page from rows selected with Query.SQL, which fetches
+// one extra row to detect whether more follow
+func NewPage[T any](q *Query, items []T, key KeyFunc[T]) (*Page[T], error) {
+	page := &Page[T]{Data: items, Pagination: Info{Limit: q.Limit}}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	if len(items) <= q.Limit {
+		return page, nil
+	}
+
+	page.Data = items[:q.Limit]
+	last := page.Data[q.Limit-1]
+	values := make([]any, len(q.Sort))
+	for i, k := range q.Sort {
+		values[i] = key(last, k.Field)
+	}
+	next, err := q.signer.encode(q, values)
+	if err != nil {
+		return nil, err
+	}
+	page.Pagination.NextCursor = next
+	page.Pagination.HasMore = true
+	return page, nil
+}
@@ -0,0 +1,351 @@
+// Package pagination parses list parameters against a per-endpoint
+// allow-list and turns them into keyset SQL. Clients page with opaque
+// signed cursors instead of offsets, so deep pages cost the same as the
+// first one.
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// Error codes returned by this package
+const (
+	CodeInvalidLimit  = "INVALID_LIMIT"
+	CodeInvalidCursor = "INVALID_CURSOR"
+	CodeInvalidSort   = "INVALID_SORT"
+	CodeInvalidFilter = "INVALID_FILTER"
+)
+
+func init() {
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeInvalidLimit,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "limit must be between 1 and {max}",
+		Description: "The limit parameter is not a number or is out of range",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeInvalidCursor,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The cursor is invalid, restart from the first page",
+		Description: "The cursor was tampered with or belongs to another endpoint, sort or filter",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeInvalidSort,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "Cannot sort by {field}",
+		Description: "The sort parameter names a field the endpoint does not sort by",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeInvalidFilter,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "Invalid filter {param}",
+		Description: "A filter names an unknown field or operator, or has a malformed value",
+	})
+}
+
+// Type is the type of a field's values
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Decimal // NUMERIC, passed as a decimal string
+	Time    // RFC 3339
+	Bool
+	UUID
+)
+
+// Op is a filter operator, written as field[op]=value. A bare field=value
+// means Eq.
+type Op string
+
+const (
+	Eq  Op = "eq"
+	Ne  Op = "ne"
+	Gt  Op = "gt"
+	Gte Op = "gte"
+	Lt  Op = "lt"
+	Lte Op = "lte"
+	In  Op = "in" // comma-separated values
+)
+
+var opSQL = map[Op]string{Eq: "=", Ne: "<>", Gt: ">", Gte: ">=", Lt: "<", Lte: "<="}
+
+// Field is a field an endpoint exposes for sorting or filtering
+type Field struct {
+	// Name is the field's name in query parameters
+	Name string
+
+	// Column is the SQL expression for the field; defaults to Name
+	Column string
+
+	Type Type
+
+	// Sortable fields may appear in sort. Their columns must be NOT NULL,
+	// since keyset conditions don't order NULLs.
+	Sortable bool
+
+	// Ops are the filter operators allowed; none means not filterable
+	Ops []Op
+
+	// Values restricts filter values, e.g. to an enum's members
+	Values []string
+}
+
+func (f *Field) column() string {
+	if f.Column != "" {
+		return f.Column
+	}
+	return f.Name
+}
+
+// Spec is an endpoint's allow-list
+type Spec struct {
+	// Name identifies the endpoint; cursors from one endpoint are
+	// rejected by others
+	Name string
+
+	Fields []Field
+
+	// DefaultSort applies when the request has no sort, e.g. "-created_at"
+	DefaultSort string
+
+	// Unique names a sortable, unique field appended to every sort so the
+	// order is total and no row is skipped or repeated between pages
+	Unique string
+
+	// Params are further query parameters the handler reads itself
+	Params []string
+}
+
+func (s *Spec) field(name string) *Field {
+	for i := range s.Fields {
+		if s.Fields[i].Name == name {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+// SortKey is one component of the sort order
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// Filter is one parsed filter; Values holds one value except for In
+type Filter struct {
+	Field  string
+	Op     Op
+	Values []any
+}
+
+// Paginator parses list requests and signs their cursors
+type Paginator struct {
+	signer       *signer
+	defaultLimit int
+	maxLimit     int
+}
+
+// New creates a paginator from configuration
+func New(cfg *config.PaginationConfig) *Paginator {
+	return &Paginator{
+		signer:       newSigner(cfg.CursorSecret),
+		defaultLimit: cfg.DefaultLimit,
+		maxLimit:     cfg.MaxLimit,
+	}
+}
+
+// filterParam matches field[op]
+var filterParam = regexp.MustCompile(`^([a-z0-9_]+)\[([a-z]+)\]$`)
+
+// Parse reads limit, cursor, sort and filters from the query string.
+// Parameters outside the spec are rejected, so typos don't silently
+// return unfiltered results. Sort is a comma-separated list of fields,
+// each prefixed with - for descending order.
+func (p *Paginator) Parse(r *http.Request, spec *Spec) (*Query, error) {
+	values := r.URL.Query()
+	q := &Query{spec: spec, signer: p.signer, Limit: p.defaultLimit}
+
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > p.maxLimit {
+			return nil, apperrors.Newf(apperrors.ErrInvalidInput, CodeInvalidLimit,
+				"limit must be between 1 and %d", p.maxLimit).WithMeta("max", p.maxLimit)
+		}
+		q.Limit = n
+	}
+
+	sort := values.Get("sort")
+	if sort == "" {
+		sort = spec.DefaultSort
+	}
+	if err := q.parseSort(sort); err != nil {
+		return nil, err
+	}
+
+	// Sorted so the cursor binding doesn't depend on parameter order
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		switch {
+		case name == "limit" || name == "cursor" || name == "sort":
+			continue
+		case slices.Contains(spec.Params, name):
+			continue
+		}
+		if err := q.parseFilter(name, values[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if v := values.Get("cursor"); v != "" {
+		after, err := p.signer.decode(v, q)
+		if err != nil {
+			return nil, err
+		}
+		q.after = after
+	}
+	return q, nil
+}
+
+func (q *Query) parseSort(sort string) error {
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(sort, ",") {
+		key := SortKey{Field: strings.TrimSpace(part)}
+		if strings.HasPrefix(key.Field, "-") {
+			key.Field, key.Desc = key.Field[1:], true
+		}
+		if key.Field == "" {
+			continue
+		}
+		f := q.spec.field(key.Field)
+		if f == nil || !f.Sortable || seen[key.Field] {
+			return apperrors.Newf(apperrors.ErrInvalidInput, CodeInvalidSort,
+				"cannot sort by %s", key.Field).WithMeta("field", key.Field)
+		}
+		seen[key.Field] = true
+		q.Sort = append(q.Sort, key)
+	}
+
+	// The unique field follows the direction of the last key
+	if u := q.spec.Unique; u != "" && !seen[u] {
+		desc := len(q.Sort) > 0 && q.Sort[len(q.Sort)-1].Desc
+		q.Sort = append(q.Sort, SortKey{Field: u, Desc: desc})
+	}
+	return nil
+}
+
+func (q *Query) parseFilter(param string, raw []string) error {
+	invalid := func(format string, args ...interface{}) error {
+		return apperrors.Newf(apperrors.ErrInvalidInput, CodeInvalidFilter, format, args...).
+			WithMeta("param", param)
+	}
+
+	name, op := param, Eq
+	if m := filterParam.FindStringSubmatch(param); m != nil {
+		name, op = m[1], Op(m[2])
+	}
+	f := q.spec.field(name)
+	if f == nil || len(f.Ops) == 0 {
+		return invalid("unknown parameter %s", param)
+	}
+	if !slices.Contains(f.Ops, op) {
+		return invalid("%s does not support the %s operator", name, op)
+	}
+	if len(raw) != 1 {
+		return invalid("%s must be given once", param)
+	}
+
+	parts := []string{raw[0]}
+	if op == In {
+		parts = strings.Split(raw[0], ",")
+	}
+	filter := Filter{Field: name, Op: op, Values: make([]any, 0, len(parts))}
+	for _, s := range parts {
+		if len(f.Values) > 0 && !slices.Contains(f.Values, s) {
+			return invalid("%s must be one of %s", name, strings.Join(f.Values, ", "))
+		}
+		v, err := parseValue(f.Type, s)
+		if err != nil {
+			return invalid("%s %v", param, err)
+		}
+		filter.Values = append(filter.Values, v)
+	}
+	q.Filters = append(q.Filters, filter)
+	return nil
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// parseValue converts a query or cursor value to the field's type
+func parseValue(t Type, s string) (any, error) {
+	switch t {
+	case Int:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return n, nil
+	case Decimal:
+		if _, ok := new(big.Rat).SetString(s); !ok || strings.ContainsAny(s, "/eE") {
+			return nil, errors.New("must be a decimal number")
+		}
+		return s, nil
+	case Time:
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, errors.New("must be an RFC 3339 timestamp")
+		}
+		return ts, nil
+	case Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, errors.New("must be true or false")
+		}
+		return b, nil
+	case UUID:
+		if !uuidPattern.MatchString(s) {
+			return nil, errors.New("must be a UUID")
+		}
+		return s, nil
+	default:
+		return s, nil
+	}
+}
+
+// formatValue is the inverse of parseValue, used for cursors
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int:
+		return strconv.Itoa(v)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case interface{ String() string }:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,166 @@
+package pagination
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query is a parsed list request
+type Query struct {
+	Limit   int
+	Sort    []SortKey
+	Filters []Filter
+
+	spec   *Spec
+	signer *signer
+
+	// after holds the sort values of the previous page's last row
+	after []any
+}
+
+// HasCursor reports whether the request continues from a previous page
+func (q *Query) HasCursor() bool {
+	return q.after != nil
+}
+
+// binding is what a cursor is signed against: a cursor only continues
+// the list it was issued for
+func (q *Query) binding() string {
+	var b strings.Builder
+	b.WriteString(q.spec.Name)
+	for _, k := range q.Sort {
+		b.WriteByte('|')
+		if k.Desc {
+			b.WriteByte('-')
+		}
+		b.WriteString(k.Field)
+	}
+	for _, f := range q.Filters {
+		b.WriteString("|" + f.Field + "[" + string(f.Op) + "]=")
+		for i, v := range f.Values {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(formatValue(v))
+		}
+	}
+	return b.String()
+}
+
+// SQL completes a SELECT with WHERE, ORDER BY and LIMIT clauses. conds
+// are the caller's own conditions, e.g. scoping to an account; they
+// reference args as $1..$n and the query numbers its parameters after
+// them. One row more than Limit is selected so NewPage can tell whether
+// another page follows.
+//
+//	sql, args := q.SQL("SELECT id, symbol, created_at FROM orders",
+//		[]string{"account_id = $1"}, accountID)
+//	rows, err := db.Query(ctx, sql, args...)
+func (q *Query) SQL(base string, conds []string, args ...any) (string, []any) {
+	b := &builder{args: args}
+	where := append([]string(nil), conds...)
+	for _, f := range q.Filters {
+		where = append(where, b.filter(q.spec.field(f.Field), f))
+	}
+	if q.after != nil {
+		where = append(where, b.keyset(q))
+	}
+
+	var sql strings.Builder
+	sql.WriteString(base)
+	if len(where) > 0 {
+		sql.WriteString(" WHERE ")
+		sql.WriteString(strings.Join(where, " AND "))
+	}
+	if len(q.Sort) > 0 {
+		sql.WriteString(" ORDER BY ")
+		for i, k := range q.Sort {
+			if i > 0 {
+				sql.WriteString(", ")
+			}
+			sql.WriteString(q.spec.field(k.Field).column())
+			if k.Desc {
+				sql.WriteString(" DESC")
+			}
+		}
+	}
+	sql.WriteString(" LIMIT " + b.add(q.Limit+1, ""))
+	return sql.String(), b.args
+}
+
+// builder numbers parameters as they are added
+type builder struct {
+	args []any
+}
+
+// add appends a parameter and returns its placeholder with an optional
+// cast
+func (b *builder) add(v any, cast string) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args)) + cast
+}
+
+// casts for types pgx doesn't send in a form Postgres compares directly
+var casts = map[Type]string{Decimal: "::numeric", UUID: "::uuid"}
+
+func (b *builder) filter(f *Field, filter Filter) string {
+	if filter.Op == In {
+		cast := casts[f.Type]
+		if cast != "" {
+			cast += "[]"
+		}
+		return f.column() + " = ANY(" + b.add(typedSlice(f.Type, filter.Values), cast) + ")"
+	}
+	return f.column() + " " + opSQL[filter.Op] + " " + b.add(filter.Values[0], casts[f.Type])
+}
+
+// keyset builds the condition for rows after the cursor position. With
+// keys a, b it is (a > $1 OR (a = $1 AND b > $2)), flipping > to < for
+// descending keys; row comparisons can't mix directions.
+func (b *builder) keyset(q *Query) string {
+	placeholders := make([]string, len(q.Sort))
+	for i, k := range q.Sort {
+		placeholders[i] = b.add(q.after[i], casts[q.spec.field(k.Field).Type])
+	}
+
+	alternatives := make([]string, len(q.Sort))
+	for i, k := range q.Sort {
+		terms := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			terms = append(terms, q.spec.field(q.Sort[j].Field).column()+" = "+placeholders[j])
+		}
+		op := " > "
+		if k.Desc {
+			op = " < "
+		}
+		terms = append(terms, q.spec.field(k.Field).column()+op+placeholders[i])
+		alternatives[i] = strings.Join(terms, " AND ")
+		if len(terms) > 1 {
+			alternatives[i] = "(" + alternatives[i] + ")"
+		}
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
+
+// typedSlice converts In values so pgx encodes them as a typed array
+func typedSlice(t Type, values []any) any {
+	switch t {
+	case Int:
+		return convert[int64](values)
+	case Bool:
+		return convert[bool](values)
+	case Time:
+		return convert[time.Time](values)
+	default:
+		return convert[string](values)
+	}
+}
+
+func convert[T any](values []any) []T {
+	out := make([]T, len(values))
+	for i, v := range values {
+		out[i] = v.(T)
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+package request
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// Error codes returned by this package
+const (
+	CodeBodyTooLarge         = "REQUEST_BODY_TOO_LARGE"
+	CodeEmptyBody            = "REQUEST_BODY_EMPTY"
+	CodeMalformedJSON        = "MALFORMED_JSON"
+	CodeUnknownField         = "UNKNOWN_FIELD"
+	CodeFieldTypeMismatch    = "FIELD_TYPE_MISMATCH"
+	CodeTrailingData         = "TRAILING_DATA"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRequestTimeout       = apperrors.CodeRequestTimeout
+)
+
+func init() {
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeBodyTooLarge,
+		Category:    apperrors.ErrInvalidInput,
+		Status:      http.StatusRequestEntityTooLarge,
+		Message:     "The request body must not exceed {limit} bytes",
+		Description: "The body is larger than the route's limit",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeEmptyBody,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The request body must not be empty",
+		Description: "A JSON body is required but none was sent",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeMalformedJSON,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The request body contains malformed JSON at position {offset}",
+		Description: "The body is not syntactically valid JSON",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeUnknownField,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The request body contains unknown field {field}",
+		Description: "The body has a field the endpoint does not accept",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeFieldTypeMismatch,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "Field {field} must be of type {expected}",
+		Description: "A field has the wrong JSON type",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeTrailingData,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The request body must contain a single JSON value",
+		Description: "Extra data follows the JSON value",
+	})
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeUnsupportedMediaType,
+		Category:    apperrors.ErrInvalidInput,
+		Status:      http.StatusUnsupportedMediaType,
+		Message:     "Content-Type must be application/json",
+		Description: "The body was sent with a media type the endpoint does not accept",
+	})
+}
+
+// Context key for the original request body
+type contextKey string
+
+const (
+	originalBodyKey contextKey = "original_body"
+	bodyLimitKey    contextKey = "body_limit"
+)
+
+// MaxBodySize limits the request body to limit bytes. Inner calls
+// replace outer ones, so a route can raise or lower a global limit.
+// Since only the innermost limit is known to apply, the declared
+// Content-Length is checked when the body is read, not here.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Re-wrap the original body so nested limits don't compound
+			ctx := r.Context()
+			body, ok := ctx.Value(originalBodyKey).(io.ReadCloser)
+			if !ok {
+				body = r.Body
+				ctx = context.WithValue(ctx, originalBodyKey, body)
+			}
+			r = r.WithContext(context.WithValue(ctx, bodyLimitKey, limit))
+			r.Body = http.MaxBytesReader(w, body, limit)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CheckContentLength rejects a request whose declared length is over
+// the body limit in effect, before any of the body is read
+func CheckContentLength(r *http.Request) error {
+	if limit, ok := r.Context().Value(bodyLimitKey).(int64); ok && r.ContentLength > limit {
+		return bodyTooLarge(limit)
+	}
+	return nil
+}
+
+// Timeout bounds the handler's context to d. If the handler hasn't
+// written a response when the deadline passes, a REQUEST_TIMEOUT error
+// is written. Handlers must honour context cancellation.
+func Timeout(d time.Duration, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && ww.Status() == 0 {
+				err := apperrors.New(apperrors.ErrExternal, CodeRequestTimeout, "").
+					WithInternal("handler exceeded %s", d).
+					AsRetryable()
+				apperrors.WriteError(w, r, err, log)
+			}
+		})
+	}
+}
+
+// DecodeJSON strictly decodes a JSON request body into dst: the body must
+// be application/json, unknown fields and trailing data are rejected, and
+// numbers keep their exact decimal text (json.Number or types with their
+// own UnmarshalJSON). Failures wrap errors.ErrInvalidInput.
+func DecodeJSON(r *http.Request, dst interface{}) error {
+	if err := CheckContentLength(r); err != nil {
+		return err
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || mediaType != "application/json" {
+			return apperrors.New(apperrors.ErrInvalidInput, CodeUnsupportedMediaType, "").
+				WithMeta("content_type", ct)
+		}
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err, dec.InputOffset())
+	}
+
+	// Anything after the first value is an error
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return bodyTooLarge(maxErr.Limit)
+		}
+		return apperrors.New(apperrors.ErrInvalidInput, CodeTrailingData, "")
+	}
+	return nil
+}
+
+// decodeError maps a json.Decoder error onto an AppError; offset is
+// the decoder position when the error occurred
+func decodeError(err error, offset int64) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		maxErr    *http.MaxBytesError
+	)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperrors.New(apperrors.ErrInvalidInput, CodeEmptyBody, "")
+	case errors.As(err, &maxErr):
+		return bodyTooLarge(maxErr.Limit)
+	case errors.As(err, &syntaxErr):
+		return apperrors.New(apperrors.ErrInvalidInput, CodeMalformedJSON, "").
+			WithMeta("offset", syntaxErr.Offset).
+			WithCause(err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperrors.New(apperrors.ErrInvalidInput, CodeMalformedJSON, "").
+			WithMeta("offset", offset).
+			WithCause(err)
+	case errors.As(err, &typeErr):
+		return apperrors.New(apperrors.ErrInvalidInput, CodeFieldTypeMismatch, "").
+			WithMeta("field", typeErr.Field).
+			WithMeta("expected", typeErr.Type.String()).
+			WithCause(err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for unknown fields
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return apperrors.New(apperrors.ErrInvalidInput, CodeUnknownField, "").
+			WithMeta("field", field)
+	default:
+		// Errors from custom UnmarshalJSON implementations keep their
+		// own classification if they have one
+		if _, ok := apperrors.AsAppError(err); ok || apperrors.Is(err, apperrors.ErrValidation) {
+			return err
+		}
+		return apperrors.New(apperrors.ErrInvalidInput, apperrors.CodeInvalidInput, "").WithCause(err)
+	}
+}
+
+// bodyTooLarge builds the error for bodies over limit bytes
+func bodyTooLarge(limit int64) error {
+	return apperrors.New(apperrors.ErrInvalidInput, CodeBodyTooLarge, fmt.Sprintf("body exceeds %d bytes", limit)).
+		WithMeta("limit", limit)
+}
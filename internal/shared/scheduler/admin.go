@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// RunRecord is one row of run history
+type RunRecord struct {
+	ID          int64      `json:"id"`
+	Task        string     `json:"task"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	Status      string     `json:"status"`
+	Instance    string     `json:"instance"`
+	Error       *string    `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// TaskStatus describes a registered task and its latest run
+type TaskStatus struct {
+	Name     string     `json:"name"`
+	Cron     string     `json:"cron"`
+	TimeZone string     `json:"time_zone"`
+	NextRun  time.Time  `json:"next_run"`
+	LastRun  *RunRecord `json:"last_run,omitempty"`
+}
+
+const runColumns = "id, task, scheduled_at, status, instance, error, started_at, finished_at"
+
+// AdminRoutes serves the task list and run history for the admin
+// listener:
+//
+//	GET /       registered tasks with next and last run
+//	GET /runs   history, filtered by ?task= and ?status=, newest first, ?limit= (default 50)
+func (s *Scheduler) AdminRoutes(log logger.Logger) http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		tasks := s.snapshot()
+		statuses := make([]TaskStatus, 0, len(tasks))
+		for _, t := range tasks {
+			st := TaskStatus{
+				Name:     t.Name,
+				Cron:     t.Cron,
+				TimeZone: t.location.String(),
+				NextRun:  t.schedule.Next(s.clock.Now().In(t.location)),
+			}
+			runs, err := s.Runs(r.Context(), t.Name, "", 1)
+			if err != nil {
+				apperrors.WriteError(w, r, err, log)
+				return
+			}
+			if len(runs) > 0 {
+				st.LastRun = &runs[0]
+			}
+			statuses = append(statuses, st)
+		}
+		writeJSON(w, statuses)
+	})
+
+	r.Get("/runs", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		limit := 50
+		if v := query.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > 1000 {
+				ve := apperrors.NewValidationError()
+				ve.AddCode("limit", apperrors.CodeFieldRange, map[string]interface{}{"min": 1, "max": 1000})
+				apperrors.WriteError(w, r, ve, log)
+				return
+			}
+			limit = n
+		}
+
+		runs, err := s.Runs(r.Context(), query.Get("task"), query.Get("status"), limit)
+		if err != nil {
+			apperrors.WriteError(w, r, err, log)
+			return
+		}
+		writeJSON(w, runs)
+	})
+	return r
+}
+
+// Runs returns run history, newest first. Empty task or status match all.
+func (s *Scheduler) Runs(ctx context.Context, task, status string, limit int) ([]RunRecord, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+runColumns+` FROM scheduled_runs
+		 WHERE ($1 = '' OR task = $1) AND ($2 = '' OR status = $2)
+		 ORDER BY scheduled_at DESC, id DESC
+		 LIMIT $3`,
+		task, status, limit,
+	)
+	if err != nil {
+		return nil, apperrors.Wrapf(apperrors.ErrDatabase, "failed to query runs: %v", err)
+	}
+	defer rows.Close()
+
+	runs := []RunRecord{}
+	for rows.Next() {
+		var run RunRecord
+		if err := rows.Scan(&run.ID, &run.Task, &run.ScheduledAt, &run.Status, &run.Instance,
+			&run.Error, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, apperrors.Wrapf(apperrors.ErrDatabase, "failed to scan run: %v", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperrors.Wrapf(apperrors.ErrDatabase, "failed to read runs: %v", err)
+	}
+	return runs, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
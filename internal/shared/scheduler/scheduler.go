@@ -0,0 +1,331 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/clock"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/metrics"
+)
+
+// maxCatchUp bounds how many missed occurrences of one task are fired
+// after downtime
+const maxCatchUp = 100
+
+// Run statuses
+const (
+	StatusRunning     = "running"
+	StatusSucceeded   = "succeeded"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+)
+
+var runsTotal = metrics.NewCounterVec(
+	"scheduler_runs_total",
+	"Scheduled task runs by task and status.",
+	"task", "status",
+)
+
+// Task is a recurring unit of work
+type Task struct {
+	Name string
+
+	// Cron is a five-field cron expression, see Schedule
+	Cron string
+
+	// TimeZone is the IANA zone the expression is evaluated in; UTC if
+	// empty. "America/New_York" keeps 16:00 at the market close across
+	// DST changes.
+	TimeZone string
+
+	// Timeout bounds one run; one hour if zero
+	Timeout time.Duration
+
+	// SkipMissed drops occurrences missed while no leader was running.
+	// By default they are fired, oldest first, when a leader starts.
+	SkipMissed bool
+
+	// Run does the work for the occurrence at scheduledAt. Long work
+	// should be enqueued as a job instead.
+	Run func(ctx context.Context, scheduledAt time.Time) error
+}
+
+type task struct {
+	Task
+	schedule *Schedule
+	location *time.Location
+}
+
+// Scheduler fires tasks on their schedule. Each occurrence is recorded
+// in scheduled_runs before it runs; the unique (task, scheduled_at) key
+// ensures an occurrence fires at most once across restarts and leader
+// changes.
+type Scheduler struct {
+	pool     *pgxpool.Pool
+	logger   logger.Logger
+	clock    clock.Clock
+	instance string
+
+	mu    sync.RWMutex
+	tasks []*task
+}
+
+// New creates a scheduler. Run it only on the elected leader, e.g. with
+// Elector.OnElected(s.Run).
+func New(pool *pgxpool.Pool, log logger.Logger) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		pool:     pool,
+		logger:   log,
+		clock:    clock.Real{},
+		instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+	}
+}
+
+// WithClock fires tasks by c instead of the wall clock. Tasks find c in
+// their context.
+func (s *Scheduler) WithClock(c clock.Clock) *Scheduler {
+	s.clock = c
+	return s
+}
+
+// Add validates and registers a task
+func (s *Scheduler) Add(t Task) error {
+	if t.Name == "" || t.Run == nil {
+		return errors.New("scheduler: task needs a name and a run function")
+	}
+	schedule, err := ParseCron(t.Cron)
+	if err != nil {
+		return fmt.Errorf("scheduler: task %s: %w", t.Name, err)
+	}
+	loc := time.UTC
+	if t.TimeZone != "" {
+		if loc, err = time.LoadLocation(t.TimeZone); err != nil {
+			return fmt.Errorf("scheduler: task %s: %w", t.Name, err)
+		}
+	}
+	if t.Timeout == 0 {
+		t.Timeout = time.Hour
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.tasks {
+		if existing.Name == t.Name {
+			return fmt.Errorf("scheduler: task %s already registered", t.Name)
+		}
+	}
+	s.tasks = append(s.tasks, &task{Task: t, schedule: schedule, location: loc})
+	return nil
+}
+
+// MustAdd is like Add but panics on error
+func (s *Scheduler) MustAdd(t Task) {
+	if err := s.Add(t); err != nil {
+		panic(err)
+	}
+}
+
+func (s *Scheduler) snapshot() []*task {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]*task{}, s.tasks...)
+}
+
+// Run schedules every task until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	tasks := s.snapshot()
+	if len(tasks) == 0 {
+		return
+	}
+
+	// Runs left open by a previous leader will never finish
+	if _, err := s.pool.Exec(ctx,
+		`UPDATE scheduled_runs
+		 SET status = 'interrupted', error = 'leader changed', finished_at = now()
+		 WHERE status = 'running'`,
+	); err != nil && ctx.Err() == nil {
+		s.logger.Error("failed to close interrupted runs", logger.Error(err))
+	}
+
+	s.logger.Info("scheduler started", logger.Int("tasks", len(tasks)))
+	ctx = clock.WithClock(ctx, s.clock)
+
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, t)
+		}()
+	}
+	wg.Wait()
+
+	s.logger.Info("scheduler stopped")
+}
+
+// loop fires one task's occurrences, starting after its last recorded run
+func (s *Scheduler) loop(ctx context.Context, t *task) {
+	log := s.logger.With(logger.String("task", t.Name))
+
+	cursor, err := s.lastScheduled(ctx, t.Name)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("failed to read run history, skipping missed runs", logger.Error(err))
+		}
+		cursor = time.Time{}
+	}
+	now := s.clock.Now()
+	if cursor.IsZero() || t.SkipMissed {
+		cursor = now
+	}
+
+	// Bound the catch-up after long downtime
+	missed := 0
+	for next := t.schedule.Next(cursor.In(t.location)); !next.IsZero() && !next.After(now); next = t.schedule.Next(next) {
+		missed++
+	}
+	if missed > maxCatchUp {
+		log.Warn("too many missed runs, firing only the latest",
+			logger.Int("missed", missed),
+			logger.Int("firing", maxCatchUp),
+		)
+		for skip := missed - maxCatchUp; skip > 0; skip-- {
+			cursor = t.schedule.Next(cursor.In(t.location))
+		}
+	}
+
+	for {
+		next := t.schedule.Next(cursor.In(t.location))
+		if next.IsZero() {
+			log.Warn("task has no future runs")
+			return
+		}
+
+		if wait := s.clock.Until(next); wait > 0 {
+			timer := s.clock.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+		}
+
+		s.fire(ctx, t, next, log)
+		cursor = next
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// lastScheduled returns the latest recorded occurrence of a task
+func (s *Scheduler) lastScheduled(ctx context.Context, name string) (time.Time, error) {
+	var last *time.Time
+	err := s.pool.QueryRow(ctx,
+		"SELECT max(scheduled_at) FROM scheduled_runs WHERE task = $1", name,
+	).Scan(&last)
+	if err != nil || last == nil {
+		return time.Time{}, err
+	}
+	return *last, nil
+}
+
+// fire claims the occurrence and runs the task. A conflict means it
+// already fired, e.g. before a restart.
+func (s *Scheduler) fire(ctx context.Context, t *task, scheduledAt time.Time, log logger.Logger) {
+	var id int64
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO scheduled_runs (task, scheduled_at, instance)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (task, scheduled_at) DO NOTHING
+		 RETURNING id`,
+		t.Name, scheduledAt, s.instance,
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Debug("occurrence already fired", logger.Time("scheduled_at", scheduledAt))
+		return
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error("failed to record run, skipping occurrence", logger.Error(err), logger.Time("scheduled_at", scheduledAt))
+		}
+		return
+	}
+
+	start := time.Now()
+	runErr := s.runTask(ctx, t, scheduledAt)
+	elapsed := time.Since(start)
+
+	status := StatusSucceeded
+	var errMsg *string
+	switch {
+	case runErr != nil && ctx.Err() != nil:
+		status = StatusInterrupted
+	case runErr != nil:
+		status = StatusFailed
+	}
+	if runErr != nil {
+		msg := runErr.Error()
+		errMsg = &msg
+	}
+	runsTotal.With(t.Name, status).Inc()
+
+	fields := []logger.Field{
+		logger.Time("scheduled_at", scheduledAt),
+		logger.Duration("duration", elapsed),
+		logger.String("status", status),
+	}
+	if runErr != nil {
+		log.Error("scheduled run failed", append(fields, logger.Error(runErr))...)
+	} else {
+		log.Info("scheduled run finished", fields...)
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if _, err := s.pool.Exec(finishCtx,
+		"UPDATE scheduled_runs SET status = $2, error = $3, finished_at = now() WHERE id = $1",
+		id, status, errMsg,
+	); err != nil {
+		log.Error("failed to record run result", logger.Error(err))
+	}
+}
+
+// runTask calls the task with its timeout, turning a panic into an error
+func (s *Scheduler) runTask(ctx context.Context, t *task, scheduledAt time.Time) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("task panicked: %v", p)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+	return t.Run(ctx, scheduledAt)
+}
+
+// PruneTask returns a daily task deleting run history older than
+// retention
+func (s *Scheduler) PruneTask(retention time.Duration) Task {
+	return Task{
+		Name: "prune-scheduled-runs",
+		Cron: "@daily",
+		Run: func(ctx context.Context, _ time.Time) error {
+			_, err := s.pool.Exec(ctx,
+				"DELETE FROM scheduled_runs WHERE finished_at < now() - $1::interval",
+				retention,
+			)
+			return err
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// ClientIdentity is the identity from a verified client certificate
+type ClientIdentity struct {
+	CommonName   string
+	Organization []string
+	DNSNames     []string
+	EmailAddress []string
+	URIs         []string
+	SerialNumber string
+	// Fingerprint is the hex SHA-256 of the certificate
+	Fingerprint string
+}
+
+// Context key for client identity
+type contextKey string
+
+const clientIdentityKey contextKey = "client_identity"
+
+// ClientIdentityFromContext returns the verified client identity, if any
+func ClientIdentityFromContext(ctx context.Context) (*ClientIdentity, bool) {
+	id, ok := ctx.Value(clientIdentityKey).(*ClientIdentity)
+	return id, ok
+}
+
+// newClientIdentity extracts the identity from a verified leaf certificate
+func newClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	sum := sha256.Sum256(cert.Raw)
+	id := &ClientIdentity{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		EmailAddress: cert.EmailAddresses,
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+	}
+	return id
+}
+
+// clientIdentityMiddleware stores the verified client certificate
+// identity in the request context
+func clientIdentityMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only chains verified against the client CA bundle count
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			id := newClientIdentity(r.TLS.VerifiedChains[0][0])
+			r = r.WithContext(context.WithValue(r.Context(), clientIdentityKey, id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireClientCert rejects requests without a verified client certificate.
+// Use it on routes reserved for institutional clients when the server
+// runs with client auth "request".
+func RequireClientCert(log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := ClientIdentityFromContext(r.Context()); !ok {
+				apperrors.WriteError(w, r, apperrors.Wrap(apperrors.ErrUnauthorized, "client certificate required"), log)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
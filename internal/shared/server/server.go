@@ -2,76 +2,219 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/lifecycle"
 	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
 )
 
 // Server represents an HTTP server
 type Server struct {
 	httpServer *http.Server
+	listeners  []net.Listener
 	logger     logger.Logger
 	config     *config.ServerConfig
+
+	// stopReload stops the TLS certificate watcher
+	stopReload context.CancelFunc
 }
 
 // NewServer creates a new HTTP server
 func NewServer(handler http.Handler, cfg *config.ServerConfig, log logger.Logger) *Server {
+	// Expose verified client certificates to handlers
+	if cfg.TLS.Enabled() && cfg.TLS.ClientAuth != "none" {
+		handler = clientIdentityMiddleware(handler)
+	}
+
+	// HTTP/1.1 always; HTTP/2 over TLS when enabled, cleartext (h2c) on request
+	var protocols http.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(cfg.TLS.Enabled())
+	protocols.SetUnencryptedHTTP2(cfg.H2C)
+
 	return &Server{
 		httpServer: &http.Server{
 			Handler:      handler,
 			ReadTimeout:  cfg.ReadTimeout,
 			WriteTimeout: cfg.WriteTimeout,
 			IdleTimeout:  cfg.IdleTimeout,
+			Protocols:    &protocols,
 		},
 		logger: log,
 		config: cfg,
 	}
 }
 
-// Start starts the HTTP server with graceful shutdown
+// Listen binds the server to the given port on all interfaces
+// without serving requests yet
+func (s *Server) Listen(port int) error {
+	return s.ListenAddr(fmt.Sprintf(":%d", port))
+}
+
+// ListenAddr binds the server without serving requests yet. It listens
+// on the host:port address, or on the sockets passed by systemd when
+// SystemdSocket is set, plus the Unix socket if one is configured.
+func (s *Server) ListenAddr(addr string) error {
+	s.httpServer.Addr = addr
+
+	// 1. Network listeners: systemd sockets or TCP
+	var listeners []net.Listener
+	if s.config.SystemdSocket {
+		sdListeners, err := systemdListeners()
+		if err != nil {
+			return err
+		}
+		listeners = sdListeners
+	} else {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", addr, err)
+		}
+		listeners = []net.Listener{ln}
+	}
+
+	// 2. Wrap network listeners with TLS if configured
+	if s.config.TLS.Enabled() {
+		tlsConfig, reloader, err := newTLSConfig(&s.config.TLS, s.logger)
+		if err != nil {
+			closeListeners(listeners)
+			return fmt.Errorf("failed to configure TLS: %w", err)
+		}
+		s.httpServer.TLSConfig = tlsConfig
+		for i, ln := range listeners {
+			listeners[i] = tls.NewListener(ln, tlsConfig)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopReload = cancel
+		go reloader.Watch(ctx, s.config.TLS.ReloadInterval)
+	}
+
+	// 3. Local Unix socket for co-located processes (never TLS)
+	if s.config.UnixSocket != "" {
+		ln, err := listenUnix(s.config.UnixSocket, s.config.UnixSocketMode)
+		if err != nil {
+			closeListeners(listeners)
+			if s.stopReload != nil {
+				s.stopReload()
+			}
+			return err
+		}
+		listeners = append(listeners, ln)
+	}
+
+	s.listeners = listeners
+	return nil
+}
+
+// Serve serves requests on all bound listeners until Shutdown is called.
+// It returns nil after a graceful shutdown.
+func (s *Server) Serve() error {
+	if len(s.listeners) == 0 {
+		return errors.New("server is not listening")
+	}
+
+	errs := make(chan error, len(s.listeners))
+	for _, ln := range s.listeners {
+		s.logger.Info("starting HTTP server",
+			logger.String("network", ln.Addr().Network()),
+			logger.String("addr", ln.Addr().String()),
+			logger.Bool("tls", s.config.TLS.Enabled() && ln.Addr().Network() != "unix"),
+			logger.Bool("h2c", s.config.H2C),
+		)
+		go func(ln net.Listener) {
+			errs <- s.httpServer.Serve(ln)
+		}(ln)
+	}
+
+	// Shutdown closes every listener, so each Serve call returns
+	var serveErr error
+	for range s.listeners {
+		if err := <-errs; err != nil && !errors.Is(err, http.ErrServerClosed) && serveErr == nil {
+			serveErr = fmt.Errorf("server error: %w", err)
+			// One failed listener takes the whole server down
+			go s.httpServer.Close()
+		}
+	}
+	return serveErr
+}
+
+// Shutdown gracefully stops the server, forcing it closed if in-flight
+// requests don't finish before ctx or ShutdownTimeout expires
+func (s *Server) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.ShutdownTimeout)
+	defer cancel()
+
+	if s.stopReload != nil {
+		s.stopReload()
+	}
+
+	// Attempt graceful shutdown
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.logger.Error("graceful shutdown failed", logger.Error(err))
+		// Force close
+		if closeErr := s.httpServer.Close(); closeErr != nil {
+			return fmt.Errorf("force close error: %w", closeErr)
+		}
+		return fmt.Errorf("graceful shutdown error: %w", err)
+	}
+
+	s.logger.Info("server stopped gracefully")
+	return nil
+}
+
+// Component returns a lifecycle component that listens on addr at start,
+// serves in the background and shuts down gracefully on stop
+func (s *Server) Component(name string, addr string, dependsOn ...string) lifecycle.Component {
+	return lifecycle.Component{
+		Name:      name,
+		DependsOn: dependsOn,
+		OnStart: func(ctx context.Context) error {
+			return s.ListenAddr(addr)
+		},
+		Run: func(ctx context.Context) error {
+			return s.Serve()
+		},
+		OnStop:      s.Shutdown,
+		StopTimeout: s.config.ShutdownTimeout,
+	}
+}
+
+// Start starts the HTTP server with graceful shutdown.
+// Applications with several components should use Component with a
+// lifecycle.Manager instead.
 func (s *Server) Start(port int) error {
-	s.httpServer.Addr = fmt.Sprintf(":%d", port)
+	if err := s.Listen(port); err != nil {
+		return err
+	}
 
 	// Channel to listen for errors
 	serverErrors := make(chan error, 1)
 
 	// Start server in a goroutine
 	go func() {
-		s.logger.Info("starting HTTP server", logger.Int("port", port))
-		serverErrors <- s.httpServer.ListenAndServe()
+		serverErrors <- s.Serve()
 	}()
 
 	// Channel to listen for interrupt signals
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(shutdown)
 
 	// Block until error or shutdown signal
 	select {
 	case err := <-serverErrors:
-		return fmt.Errorf("server error: %w", err)
+		return err
 	case sig := <-shutdown:
 		s.logger.Info("shutdown signal received", logger.String("signal", sig.String()))
-
-		// Create context with timeout for shutdown
-		ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
-		defer cancel()
-
-		// Attempt graceful shutdown
-		if err := s.httpServer.Shutdown(ctx); err != nil {
-			s.logger.Error("graceful shutdown failed", logger.Error(err))
-			// Force close
-			if closeErr := s.httpServer.Close(); closeErr != nil {
-				return fmt.Errorf("force close error: %w", closeErr)
-			}
-			return fmt.Errorf("graceful shutdown error: %w", err)
-		}
-
-		s.logger.Info("server stopped gracefully")
-		return nil
+		return s.Shutdown(context.Background())
 	}
 }
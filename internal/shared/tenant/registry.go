@@ -0,0 +1,207 @@
+package tenant
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/cache"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/config"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// Tenant is a brokerage client of the platform
+type Tenant struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Settings  json.RawMessage `json:"settings"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// CodeInvalidSettings is returned for tenant settings Apply rejects
+const CodeInvalidSettings = "TENANT_SETTINGS_INVALID"
+
+func init() {
+	apperrors.RegisterCode(apperrors.CodeDefinition{
+		Code:        CodeInvalidSettings,
+		Category:    apperrors.ErrInvalidInput,
+		Message:     "The tenant settings are invalid",
+		Description: "Tenant settings with an unknown key, a wrong type or a value outside the allowed range",
+	})
+}
+
+// overrides is the shape of a tenant's settings: config sections that
+// tenants may override, keyed like their JSON names
+type overrides struct {
+	Trading config.TradingConfig `json:"trading"`
+}
+
+// Apply layers tenant settings over the base configuration. Only the
+// keys present in settings change; unknown keys are rejected so typos
+// don't silently fall back to defaults.
+func Apply(base *config.Config, settings json.RawMessage) (*config.Config, error) {
+	o := overrides{Trading: base.Trading}
+	o.Trading.Instruments = append([]string(nil), base.Trading.Instruments...)
+
+	if len(bytes.TrimSpace(settings)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(settings))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&o); err != nil {
+			return nil, apperrors.New(apperrors.ErrInvalidInput, CodeInvalidSettings, "").WithCause(err)
+		}
+	}
+	if err := o.Trading.Validate(); err != nil {
+		return nil, apperrors.New(apperrors.ErrInvalidInput, CodeInvalidSettings, "").WithCause(err)
+	}
+
+	cfg := *base
+	cfg.Trading = o.Trading
+	return &cfg, nil
+}
+
+// CacheName addresses the tenant cache in invalidation messages
+const CacheName = "tenants"
+
+// NewCache creates the cache used by Registry.WithCache; tenant settings
+// hold the fee schedule and risk limits read on every order
+func NewCache() *cache.Cache[string, *Tenant] {
+	return cache.New[string, *Tenant](CacheName, cache.Options{
+		Size:        1000,
+		TTL:         5 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+	})
+}
+
+// Registry stores tenants and resolves their effective configuration
+type Registry struct {
+	pool  *pgxpool.Pool
+	base  *config.Config
+	cache *cache.Cache[string, *Tenant]
+}
+
+// NewRegistry creates a tenant registry over the base configuration
+func NewRegistry(pool *pgxpool.Pool, base *config.Config) *Registry {
+	return &Registry{pool: pool, base: base}
+}
+
+// WithCache serves Get and Config from c. Attach c to the cache
+// invalidator so changes from any instance evict it.
+func (r *Registry) WithCache(c *cache.Cache[string, *Tenant]) *Registry {
+	r.cache = c
+	return r
+}
+
+// Get returns a tenant by ID. Inside a transaction it reads the database
+// directly, so the transaction's own writes are visible.
+func (r *Registry) Get(ctx context.Context, id string) (*Tenant, error) {
+	if r.cache == nil || database.GetTx(ctx) != nil {
+		return r.get(ctx, id)
+	}
+	t, err := r.cache.Get(ctx, id, func(ctx context.Context) (*Tenant, error) {
+		return r.get(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	clone := *t
+	return &clone, nil
+}
+
+func (r *Registry) get(ctx context.Context, id string) (*Tenant, error) {
+	t := &Tenant{}
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx,
+		"SELECT id, name, settings, created_at, updated_at FROM tenants WHERE id = $1", id,
+	).Scan(&t.ID, &t.Name, &t.Settings, &t.CreatedAt, &t.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperrors.Wrapf(apperrors.ErrNotFound, "tenant %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get tenant: %v", apperrors.ErrDatabase, err)
+	}
+	return t, nil
+}
+
+// Create adds a tenant. Settings are validated against the current base
+// configuration.
+func (r *Registry) Create(ctx context.Context, id, name string, settings json.RawMessage) (*Tenant, error) {
+	ve := apperrors.NewValidationError()
+	if !ValidID(id) {
+		ve.Add("id", "must be 2-63 lowercase letters, digits or hyphens")
+	}
+	if name == "" {
+		ve.AddCode("name", apperrors.CodeFieldRequired, nil)
+	}
+	if err := ve.ErrOrNil(); err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(settings)) == 0 {
+		settings = json.RawMessage("{}")
+	}
+	if _, err := Apply(r.base, settings); err != nil {
+		return nil, err
+	}
+
+	t := &Tenant{ID: id, Name: name}
+	err := database.GetQuerier(ctx, r.pool).QueryRow(ctx,
+		`INSERT INTO tenants (id, name, settings) VALUES ($1, $2, $3)
+		 RETURNING settings, created_at, updated_at`,
+		id, name, string(settings),
+	).Scan(&t.Settings, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if apperrors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, apperrors.Wrapf(apperrors.ErrAlreadyExists, "tenant %s", id)
+		}
+		return nil, fmt.Errorf("%w: failed to create tenant: %v", apperrors.ErrDatabase, err)
+	}
+	// Drop any cached not-found result
+	if err := cache.Invalidate(ctx, r.pool, CacheName, id); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UpdateSettings replaces a tenant's settings
+func (r *Registry) UpdateSettings(ctx context.Context, id string, settings json.RawMessage) error {
+	if _, err := Apply(r.base, settings); err != nil {
+		return err
+	}
+	tag, err := database.GetQuerier(ctx, r.pool).Exec(ctx,
+		"UPDATE tenants SET settings = $2, updated_at = now() WHERE id = $1",
+		id, string(settings),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: failed to update tenant: %v", apperrors.ErrDatabase, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return apperrors.Wrapf(apperrors.ErrNotFound, "tenant %s", id)
+	}
+	return cache.Invalidate(ctx, r.pool, CacheName, id)
+}
+
+// Config returns the configuration in effect for a tenant
+func (r *Registry) Config(ctx context.Context, id string) (*config.Config, error) {
+	t, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return Apply(r.base, t.Settings)
+}
+
+// Current returns the configuration for the tenant in ctx
+func (r *Registry) Current(ctx context.Context) (*config.Config, error) {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return nil, apperrors.Wrap(apperrors.ErrUnauthorized, "no tenant in context")
+	}
+	return r.Config(ctx, id)
+}
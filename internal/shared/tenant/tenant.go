@@ -0,0 +1,50 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+	"github.com/F1sssss/Perfect_Trade/internal/shared/logger"
+)
+
+// Default is the tenant created by the tenancy migration; data from
+// before multi-tenancy belongs to it
+const Default = "default"
+
+// idPattern matches tenant IDs; the migration enforces the same rule
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// ValidID reports whether id is a well-formed tenant ID
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// WithID returns a context scoped to a tenant. Transactions started with
+// it only see that tenant's rows. Use it rather than the database
+// functions it wraps; the value is stored by the database package because
+// the transaction manager reads it and this package imports database.
+func WithID(ctx context.Context, id string) context.Context {
+	return database.WithTenantID(ctx, id)
+}
+
+// FromContext returns the tenant set with WithID
+func FromContext(ctx context.Context) (string, bool) {
+	return database.TenantID(ctx)
+}
+
+// Require rejects requests that have no tenant, i.e. that were not
+// authenticated. Mount it on routes that read tenant data.
+func Require(log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := FromContext(r.Context()); !ok {
+				apperrors.WriteError(w, r, apperrors.Wrap(apperrors.ErrUnauthorized, "authentication required"), log)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
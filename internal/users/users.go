@@ -0,0 +1,99 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/F1sssss/Perfect_Trade/internal/shared/database"
+	apperrors "github.com/F1sssss/Perfect_Trade/internal/shared/errors"
+)
+
+// Roles
+const (
+	RoleAdmin  = "admin"
+	RoleTrader = "trader"
+	RoleViewer = "viewer"
+)
+
+// MinPasswordLength is the shortest accepted password
+const MinPasswordLength = 12
+
+// User is an account that can sign in
+type User struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Repository stores users
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new user repository
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Create validates the input, hashes the password and inserts the user.
+// Users belong to the tenant of the transaction, so call it inside
+// WithTransaction with a tenant in ctx.
+func (r *Repository) Create(ctx context.Context, email, password, role string) (*User, error) {
+	email = strings.TrimSpace(email)
+
+	ve := apperrors.NewValidationError()
+	if _, err := mail.ParseAddress(email); err != nil || email == "" {
+		ve.AddCode("email", apperrors.CodeFieldInvalid, nil)
+	}
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		ve.Addf("password", "must be at least %d characters", MinPasswordLength)
+	}
+	// bcrypt ignores bytes past 72
+	if len(password) > 72 {
+		ve.AddCode("password", apperrors.CodeFieldTooLong, map[string]interface{}{"max": 72})
+	}
+	switch role {
+	case RoleAdmin, RoleTrader, RoleViewer:
+	default:
+		ve.AddCode("role", apperrors.CodeFieldInvalid, nil)
+	}
+	if err := ve.ErrOrNil(); err != nil {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperrors.Wrap(apperrors.ErrInternal, "failed to hash password")
+	}
+
+	user := &User{Email: email, Role: role}
+	err = database.GetQuerier(ctx, r.pool).QueryRow(ctx,
+		`INSERT INTO users (email, password_hash, role)
+		 VALUES ($1, $2, $3)
+		 RETURNING id, tenant_id, created_at`,
+		email, string(hash), role,
+	).Scan(&user.ID, &user.TenantID, &user.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperrors.Wrapf(apperrors.ErrAlreadyExists, "user %s", email)
+		}
+		return nil, fmt.Errorf("%w: failed to create user: %v", apperrors.ErrDatabase, err)
+	}
+	return user, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return apperrors.As(err, &pgErr) && pgErr.Code == "23505"
+}